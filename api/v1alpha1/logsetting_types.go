@@ -17,16 +17,25 @@ limitations under the License.
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// +kubebuilder:validation:Enum:=LogLevelNotSet;LogLevelInfo;LogLevelDebug;LogLevelVerbose
+// LogLevel is either one of the built-in log levels or the name of a
+// level declared in LogSettingSpec CustomLevels.
+// +kubebuilder:validation:MaxLength=63
 type LogLevel string
 
 const (
 	// LogLevelNotSet indicates log severity is not set. Default configuration will apply.
 	LogLevelNotSet = LogLevel("LogLevelNotSet")
 
+	// LogLevelError indicates only errors are logged
+	LogLevelError = LogLevel("LogLevelError")
+
+	// LogLevelWarning indicates only warnings and errors are logged
+	LogLevelWarning = LogLevel("LogLevelWarning")
+
 	// LogLevelInfo indicates log severity info (default to V(0)) is set
 	LogLevelInfo = LogLevel("LogLevelInfo")
 
@@ -37,6 +46,35 @@ const (
 	LogLevelVerbose = LogLevel("LogLevelVerbose")
 )
 
+// PodLogLevelAnnotation can be set on a Pod to override, for that Pod only,
+// the log level set by LogSetting. Value is a LogLevel, or the short name
+// of a built-in one (error, warning, info, debug or verbose).
+const PodLogLevelAnnotation = "loglevel.projectsveltos.io/level"
+
+// +kubebuilder:validation:Enum:=text;json
+type LogFormat string
+
+const (
+	// LogFormatText is klog text format
+	LogFormatText = LogFormat("text")
+
+	// LogFormatJSON is JSON format, one object per line
+	LogFormatJSON = LogFormat("json")
+)
+
+// CustomLevel is a user-defined named log level
+// +kubebuilder:validation:XValidation:rule="!(self.name in ['error', 'warning', 'info', 'debug', 'verbose'])",message="name is reserved for a built-in log level"
+type CustomLevel struct {
+	// Name of the log level. It can be used as LogLevel in any configuration.
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9-]*$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// Verbosity is the klog verbosity the log level maps to
+	// +kubebuilder:validation:Minimum=0
+	Verbosity int32 `json:"verbosity"`
+}
+
 // Component identifies the entity that has registered to have
 // log level managed via LogSetting
 type Component struct {
@@ -48,21 +86,321 @@ type Component struct {
 	Identifier string `json:"identifier"`
 }
 
+// Schedule defines when a log level configuration is active. Either Cron and
+// Duration or a daily window (Start and End) must be set.
+type Schedule struct {
+	// Cron is a standard cron expression (5 fields) indicating when the
+	// configuration becomes active.
+	// +optional
+	Cron string `json:"cron,omitempty"`
+
+	// Duration is how long the configuration stays active after each
+	// Cron activation.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// Start is the time of day, in HH:MM format, the daily window opens.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	Start string `json:"start,omitempty"`
+
+	// End is the time of day, in HH:MM format, the daily window closes.
+	// If End is not after Start, the window spans midnight.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	// +optional
+	End string `json:"end,omitempty"`
+
+	// TimeZone is the IANA time zone name Cron and daily window are
+	// evaluated in. [Default: UTC]
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// ErrorEscalation temporarily raises log severity to debug when errors are
+// logged in bursts.
+type ErrorEscalation struct {
+	// Threshold is the number of errors which, when exceeded within Window,
+	// raises log severity to debug.
+	// +kubebuilder:validation:Minimum=1
+	Threshold int32 `json:"threshold"`
+
+	// Window is the period errors are counted over.
+	Window metav1.Duration `json:"window"`
+
+	// CoolDown is how long log severity stays at debug once raised.
+	CoolDown metav1.Duration `json:"coolDown"`
+}
+
+// Sampling limits how many records above Info are logged. For every call site,
+// the First records logged within a second are kept. After that, one record
+// every Thereafter is kept.
+type Sampling struct {
+	// First is the number of records logged per second per call site
+	// before sampling starts.
+	// +kubebuilder:validation:Minimum=1
+	First int32 `json:"first"`
+
+	// Thereafter is the sampling rate once First records have been logged
+	// within the same second: one record every Thereafter is kept.
+	// +kubebuilder:validation:Minimum=1
+	Thereafter int32 `json:"thereafter"`
+}
+
+// ObjectTarget identifies objects whose reconciliations get a different log
+// severity than the rest of the component.
+type ObjectTarget struct {
+	// Group of the object. Empty for core resources.
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// Version of the object. If not set, any version matches.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Kind of the object
+	Kind string `json:"kind"`
+
+	// Namespace of the object. If not set, objects in any namespace match.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object. If not set, objects with any name match.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector, if set, restricts matching to objects with these labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// LogLevel is the log severity for matching objects. [Default: Debug]
+	// +optional
+	LogLevel LogLevel `json:"logLevel,omitempty"`
+}
+
+// RequestDebug enables elevated logging for single requests carrying a debug
+// token.
+type RequestDebug struct {
+	// SecretRef references the Secret containing the debug token
+	SecretRef corev1.SecretReference `json:"secretRef"`
+
+	// Key is the key in Secret data containing the debug token. [Default: token]
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// LogLevel is the log severity for requests carrying the debug token. [Default: Debug]
+	// +optional
+	LogLevel LogLevel `json:"logLevel,omitempty"`
+}
+
+// KlogFlags contains klog flags which can be changed at run-time. Flags not
+// set keep their original value.
+type KlogFlags struct {
+	// StderrThreshold is the severity at or above which logs go to stderr
+	// (klog stderrthreshold flag)
+	// +kubebuilder:validation:Enum:=INFO;WARNING;ERROR;FATAL
+	// +optional
+	StderrThreshold *string `json:"stderrThreshold,omitempty"`
+
+	// LogFile is the file logs are written to (klog log_file flag)
+	// +optional
+	LogFile *string `json:"logFile,omitempty"`
+
+	// LogFileMaxSize is the maximum size, in megabytes, of the log file.
+	// 0 means no limit (klog log_file_max_size flag)
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	LogFileMaxSize *int64 `json:"logFileMaxSize,omitempty"`
+
+	// AddDirHeader, if true, adds the file directory to the header of log
+	// messages (klog add_dir_header flag)
+	// +optional
+	AddDirHeader *bool `json:"addDirHeader,omitempty"`
+
+	// OneOutput, if true, only writes logs to their native severity level,
+	// instead of also writing to each lower severity level (klog one_output flag)
+	// +optional
+	OneOutput *bool `json:"oneOutput,omitempty"`
+}
+
+// VerbosityMapping maps log levels to klog verbosity. Levels not set keep
+// the value defined elsewhere.
+type VerbosityMapping struct {
+	// Info is the klog verbosity for LogLevelInfo. It also applies to
+	// LogLevelWarning and LogLevelError.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Info *int32 `json:"info,omitempty"`
+
+	// Debug is the klog verbosity for LogLevelDebug
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Debug *int32 `json:"debug,omitempty"`
+
+	// Verbose is the klog verbosity for LogLevelVerbose
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Verbose *int32 `json:"verbose,omitempty"`
+}
+
 // ComponentConfiguration is the debugging configuration to be applied to a Sveltos component.
+// A configuration targets either a Component or, with TagSelector, all components
+// registered with matching tags. When both kinds match, the one for the exact
+// component takes precedence, while a tag match takes precedence over a
+// Component with an Identifier pattern. Nodes, NodeSelector and ReplicaPercentage further
+// limit where the configuration applies: among entries otherwise equally
+// specific, one limited to nodes takes precedence, then one limited to a share
+// of replicas.
+// +kubebuilder:validation:XValidation:rule="!has(self.tagSelector) || !has(self.component) || (size(self.component.__namespace__) == 0 && size(self.component.identifier) == 0)",message="component and tagSelector are mutually exclusive"
 type ComponentConfiguration struct {
 	// Component indicates which component the configuration applies to.
+	// Identifier can be a glob pattern, like "capi-*", or "*" for all
+	// components in Namespace. Namespace "*" matches all namespaces, so
+	// Namespace and Identifier both "*" is the cluster-wide default.
+	// When more entries match a component, the most specific one applies.
+	// +optional
 	Component Component `json:"component"`
 
+	// TagSelector, if set, makes the configuration apply to all components
+	// registered with tags matching it.
+	// +optional
+	TagSelector *metav1.LabelSelector `json:"tagSelector,omitempty"`
+
+	// Nodes, if set, limits the configuration to components running on
+	// one of these nodes.
+	// +listType=set
+	// +optional
+	Nodes []string `json:"nodes,omitempty"`
+
+	// NodeSelector, if set, limits the configuration to components running
+	// on nodes whose labels match it.
+	// +optional
+	NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+
+	// ReplicaPercentage, if set, limits the configuration to this percentage
+	// of component replicas. Each replica decides whether it is selected
+	// hashing its Pod name with the entry, so the same replicas stay selected
+	// as long as the entry targets the same components.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ReplicaPercentage *int32 `json:"replicaPercentage,omitempty"`
+
 	// LogLevel is the log severity above which logs are sent to the stdout. [Default: Info]
 	LogLevel LogLevel `json:"logLevel,omitempty"`
+
+	// Mapping, if set, maps log levels to klog verbosity for this component.
+	// It takes precedence over LogSettingSpec Mapping and over values set by
+	// the component itself.
+	// +optional
+	Mapping *VerbosityMapping `json:"mapping,omitempty"`
+
+	// Format is the log output format. [Default: text]
+	// +optional
+	Format LogFormat `json:"format,omitempty"`
+
+	// KlogFlags, if set, overrides klog flags. Original values are restored
+	// when the entry is removed.
+	// +optional
+	KlogFlags *KlogFlags `json:"klogFlags,omitempty"`
+
+	// Schedule, if set, limits when LogLevel applies. Outside the schedule
+	// the component falls back to the default log severity.
+	// +optional
+	Schedule *Schedule `json:"schedule,omitempty"`
+
+	// ErrorEscalation, if set, temporarily raises log severity to debug when
+	// errors are logged in bursts. It only takes effect for loggers created
+	// with LogSetter NewErrorEscalationLogger.
+	// +optional
+	ErrorEscalation *ErrorEscalation `json:"errorEscalation,omitempty"`
+
+	// DumpTrigger, when changed, makes the component dump to stderr the verbose
+	// logs kept by its flight recorder loggers (see LogSetter NewFlightRecorderLogger).
+	// Any value can be used, for instance current time.
+	// +optional
+	DumpTrigger string `json:"dumpTrigger,omitempty"`
+
+	// Sampling, if set, limits the number of debug and verbose records logged.
+	// It only takes effect for loggers created with LogSetter NewSamplingLogger.
+	// +optional
+	Sampling *Sampling `json:"sampling,omitempty"`
+
+	// Objects, if set, lists objects whose reconciliations are logged with
+	// their own log severity. It only takes effect for loggers returned by
+	// LogSetter LoggerFor.
+	// +kubebuilder:validation:MaxItems=16
+	// +listType=atomic
+	// +optional
+	Objects []ObjectTarget `json:"objects,omitempty"`
+
+	// RequestDebug, if set, enables elevated logging for requests carrying
+	// the debug token. It only takes effect for servers using LogSetter HTTP
+	// middleware or gRPC interceptors.
+	// +optional
+	RequestDebug *RequestDebug `json:"requestDebug,omitempty"`
 }
 
 // LogSettingSpec defines the desired state of LogSetting
+// +kubebuilder:validation:XValidation:rule="!has(self.configuration) || self.configuration.all(c, !has(c.logLevel) || c.logLevel in ['LogLevelNotSet', 'LogLevelError', 'LogLevelWarning', 'LogLevelInfo', 'LogLevelDebug', 'LogLevelVerbose'] || (has(self.customLevels) && self.customLevels.exists(l, l.name == c.logLevel)))",message="logLevel must be a built-in log level or declared in customLevels"
+// +kubebuilder:validation:XValidation:rule="!has(self.configuration) || self.configuration.all(c, !has(c.objects) || c.objects.all(o, !has(o.logLevel) || o.logLevel in ['LogLevelNotSet', 'LogLevelError', 'LogLevelWarning', 'LogLevelInfo', 'LogLevelDebug', 'LogLevelVerbose'] || (has(self.customLevels) && self.customLevels.exists(l, l.name == o.logLevel))))",message="objects logLevel must be a built-in log level or declared in customLevels"
+// +kubebuilder:validation:XValidation:rule="!has(self.configuration) || self.configuration.all(c, !has(c.requestDebug) || !has(c.requestDebug.logLevel) || c.requestDebug.logLevel in ['LogLevelNotSet', 'LogLevelError', 'LogLevelWarning', 'LogLevelInfo', 'LogLevelDebug', 'LogLevelVerbose'] || (has(self.customLevels) && self.customLevels.exists(l, l.name == c.requestDebug.logLevel)))",message="requestDebug logLevel must be a built-in log level or declared in customLevels"
 type LogSettingSpec struct {
 	// Configuration contains log level configuration as granular as per component.
+	// +kubebuilder:validation:MaxItems=256
 	// +listType=atomic
 	// +optional
 	Configuration []ComponentConfiguration `json:"configuration,omitempty"`
+
+	// CustomLevels declares user-defined log levels, in addition to the
+	// built-in ones.
+	// +kubebuilder:validation:MaxItems=64
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	CustomLevels []CustomLevel `json:"customLevels,omitempty"`
+
+	// Mapping, if set, maps log levels to klog verbosity for all components.
+	// It takes precedence over values set by components themselves.
+	// +optional
+	Mapping *VerbosityMapping `json:"mapping,omitempty"`
+
+	// RevisionHistoryLimit is the number of revisions kept in Status. [Default: 10]
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+}
+
+// Revision is a snapshot of the log level configuration
+type Revision struct {
+	// Revision is the sequence number of this snapshot
+	Revision int64 `json:"revision"`
+
+	// Author is who made the change. Empty for configurations not set via
+	// the helper CLI, recorded before the CLI changes them.
+	// +optional
+	Author string `json:"author,omitempty"`
+
+	// Time is when the change was made
+	Time metav1.Time `json:"time"`
+
+	// Configuration is the log level configuration set by the change
+	// +kubebuilder:validation:MaxItems=256
+	// +listType=atomic
+	// +optional
+	Configuration []ComponentConfiguration `json:"configuration,omitempty"`
+}
+
+// LogSettingStatus defines the observed state of LogSetting
+type LogSettingStatus struct {
+	// Revisions contains the most recent configurations, oldest first.
+	// A revision is recorded every time configuration is changed via the
+	// helper CLI. Configuration found before a change is recorded as well,
+	// if it differs from the latest revision.
+	// +kubebuilder:validation:MaxItems=100
+	// +listType=atomic
+	// +optional
+	Revisions []Revision `json:"revisions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -73,7 +411,8 @@ type LogSetting struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec LogSettingSpec `json:"spec,omitempty"`
+	Spec   LogSettingSpec   `json:"spec,omitempty"`
+	Status LogSettingStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -22,6 +22,7 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -44,6 +45,63 @@ func (in *Component) DeepCopy() *Component {
 func (in *ComponentConfiguration) DeepCopyInto(out *ComponentConfiguration) {
 	*out = *in
 	out.Component = in.Component
+	if in.TagSelector != nil {
+		in, out := &in.TagSelector, &out.TagSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaPercentage != nil {
+		in, out := &in.ReplicaPercentage, &out.ReplicaPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Mapping != nil {
+		in, out := &in.Mapping, &out.Mapping
+		*out = new(VerbosityMapping)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KlogFlags != nil {
+		in, out := &in.KlogFlags, &out.KlogFlags
+		*out = new(KlogFlags)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(Schedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ErrorEscalation != nil {
+		in, out := &in.ErrorEscalation, &out.ErrorEscalation
+		*out = new(ErrorEscalation)
+		**out = **in
+	}
+	if in.Sampling != nil {
+		in, out := &in.Sampling, &out.Sampling
+		*out = new(Sampling)
+		**out = **in
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ObjectTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequestDebug != nil {
+		in, out := &in.RequestDebug, &out.RequestDebug
+		*out = new(RequestDebug)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentConfiguration.
@@ -56,12 +114,85 @@ func (in *ComponentConfiguration) DeepCopy() *ComponentConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CustomLevel) DeepCopyInto(out *CustomLevel) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CustomLevel.
+func (in *CustomLevel) DeepCopy() *CustomLevel {
+	if in == nil {
+		return nil
+	}
+	out := new(CustomLevel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ErrorEscalation) DeepCopyInto(out *ErrorEscalation) {
+	*out = *in
+	out.Window = in.Window
+	out.CoolDown = in.CoolDown
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ErrorEscalation.
+func (in *ErrorEscalation) DeepCopy() *ErrorEscalation {
+	if in == nil {
+		return nil
+	}
+	out := new(ErrorEscalation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KlogFlags) DeepCopyInto(out *KlogFlags) {
+	*out = *in
+	if in.StderrThreshold != nil {
+		in, out := &in.StderrThreshold, &out.StderrThreshold
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogFile != nil {
+		in, out := &in.LogFile, &out.LogFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogFileMaxSize != nil {
+		in, out := &in.LogFileMaxSize, &out.LogFileMaxSize
+		*out = new(int64)
+		**out = **in
+	}
+	if in.AddDirHeader != nil {
+		in, out := &in.AddDirHeader, &out.AddDirHeader
+		*out = new(bool)
+		**out = **in
+	}
+	if in.OneOutput != nil {
+		in, out := &in.OneOutput, &out.OneOutput
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KlogFlags.
+func (in *KlogFlags) DeepCopy() *KlogFlags {
+	if in == nil {
+		return nil
+	}
+	out := new(KlogFlags)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogSetting) DeepCopyInto(out *LogSetting) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSetting.
@@ -120,8 +251,25 @@ func (in *LogSettingSpec) DeepCopyInto(out *LogSettingSpec) {
 	if in.Configuration != nil {
 		in, out := &in.Configuration, &out.Configuration
 		*out = make([]ComponentConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CustomLevels != nil {
+		in, out := &in.CustomLevels, &out.CustomLevels
+		*out = make([]CustomLevel, len(*in))
 		copy(*out, *in)
 	}
+	if in.Mapping != nil {
+		in, out := &in.Mapping, &out.Mapping
+		*out = new(VerbosityMapping)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSettingSpec.
@@ -133,3 +281,149 @@ func (in *LogSettingSpec) DeepCopy() *LogSettingSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogSettingStatus) DeepCopyInto(out *LogSettingStatus) {
+	*out = *in
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]Revision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogSettingStatus.
+func (in *LogSettingStatus) DeepCopy() *LogSettingStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LogSettingStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectTarget) DeepCopyInto(out *ObjectTarget) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectTarget.
+func (in *ObjectTarget) DeepCopy() *ObjectTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestDebug) DeepCopyInto(out *RequestDebug) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestDebug.
+func (in *RequestDebug) DeepCopy() *RequestDebug {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestDebug)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Revision) DeepCopyInto(out *Revision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make([]ComponentConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Revision.
+func (in *Revision) DeepCopy() *Revision {
+	if in == nil {
+		return nil
+	}
+	out := new(Revision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sampling) DeepCopyInto(out *Sampling) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sampling.
+func (in *Sampling) DeepCopy() *Sampling {
+	if in == nil {
+		return nil
+	}
+	out := new(Sampling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Schedule) DeepCopyInto(out *Schedule) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Schedule.
+func (in *Schedule) DeepCopy() *Schedule {
+	if in == nil {
+		return nil
+	}
+	out := new(Schedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VerbosityMapping) DeepCopyInto(out *VerbosityMapping) {
+	*out = *in
+	if in.Info != nil {
+		in, out := &in.Info, &out.Info
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Verbose != nil {
+		in, out := &in.Verbose, &out.Verbose
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VerbosityMapping.
+func (in *VerbosityMapping) DeepCopy() *VerbosityMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(VerbosityMapping)
+	in.DeepCopyInto(out)
+	return out
+}
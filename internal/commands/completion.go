@@ -0,0 +1,81 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	docopt "github.com/docopt/docopt-go"
+	"github.com/go-logr/logr"
+
+	"github.com/gianlucam76/pod-log-level/internal/commands/completion"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+// Completion generates shell completion scripts.
+func Completion(ctx context.Context, args []string, logger logr.Logger) error {
+	doc := `Usage:
+	helper completion <command> [<args>...]
+
+	bash          Generate completion script for bash.
+	zsh           Generate completion script for zsh.
+	fish          Generate completion script for fish.
+
+Options:
+	-h --help      Show this screen.
+
+Description:
+	See 'helper completion <command> --help' to read about a specific subcommand.
+  `
+
+	parser := &docopt.Parser{
+		HelpHandler:   docopt.PrintHelpAndExit,
+		OptionsFirst:  true,
+		SkipHelpFlags: false,
+	}
+
+	opts, err := parser.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		var userError docopt.UserError
+		if errors.As(err, &userError) {
+			logger.V(lib.LogInfo).Info(fmt.Sprintf(
+				"Invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand.\n",
+				strings.Join(os.Args[1:], " "),
+			))
+		}
+		os.Exit(1)
+	}
+
+	command := opts["<command>"].(string)
+	arguments := append([]string{"completion", command}, opts["<args>"].([]string)...)
+
+	switch command {
+	case "bash", "zsh", "fish":
+		return completion.Script(ctx, arguments, command)
+	case "__complete":
+		return completion.Complete(ctx, arguments)
+	default:
+		//nolint: forbidigo // print doc
+		fmt.Println(doc)
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	docopt "github.com/docopt/docopt-go"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+const (
+	namespaceCandidates  = "namespace"
+	identifierCandidates = "identifier"
+	levelCandidates      = "level"
+)
+
+// builtinLevels are the built-in log levels accepted by helper log-level set --level
+var builtinLevels = []string{"error", "warning", "info", "debug", "verbose"}
+
+// componentsFromLogSetting returns all components currently listed in the
+// default LogSetting instance.
+func componentsFromLogSetting(ctx context.Context) ([]v1alpha1.Component, error) {
+	instance := utils.GetAccessInstance()
+	if instance == nil {
+		return nil, errors.New("cluster is not reachable")
+	}
+
+	dc, err := instance.GetLogSetting(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return make([]v1alpha1.Component, 0), nil
+		}
+		return nil, err
+	}
+
+	components := make([]v1alpha1.Component, len(dc.Spec.Configuration))
+	for i := range dc.Spec.Configuration {
+		components[i] = dc.Spec.Configuration[i].Component
+	}
+
+	return components, nil
+}
+
+// getNamespaces returns namespaces of components listed in the LogSetting
+// instance as well as all namespaces existing in the cluster.
+func getNamespaces(ctx context.Context) ([]string, error) {
+	components, err := componentsFromLogSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]bool)
+	for i := range components {
+		candidates[components[i].Namespace] = true
+	}
+
+	// A component can live in any namespace. If namespaces cannot be listed
+	// (for instance RBAC does not allow it) only LogSetting entries are offered.
+	namespaces, err := utils.GetAccessInstance().ListNamespaces(ctx)
+	if err == nil {
+		for i := range namespaces {
+			candidates[namespaces[i]] = true
+		}
+	}
+
+	return sortedKeys(candidates), nil
+}
+
+// getIdentifiers returns identifiers of components listed in the LogSetting
+// instance. If namespace is not empty, only components in that namespace are
+// considered.
+func getIdentifiers(ctx context.Context, namespace string) ([]string, error) {
+	components, err := componentsFromLogSetting(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make(map[string]bool)
+	for i := range components {
+		if namespace == "" || components[i].Namespace == namespace {
+			candidates[components[i].Identifier] = true
+		}
+	}
+
+	return sortedKeys(candidates), nil
+}
+
+// getLevels returns built-in log levels followed by custom levels declared
+// in the LogSetting instance. If LogSetting cannot be read, for instance
+// because cluster is not reachable, only built-in levels are returned.
+func getLevels(ctx context.Context) ([]string, error) {
+	levels := make([]string, len(builtinLevels))
+	copy(levels, builtinLevels)
+
+	instance := utils.GetAccessInstance()
+	if instance == nil {
+		return levels, nil
+	}
+	dc, err := instance.GetLogSetting(ctx)
+	if err != nil {
+		return levels, nil
+	}
+
+	custom := make(map[string]bool)
+	for i := range dc.Spec.CustomLevels {
+		custom[dc.Spec.CustomLevels[i].Name] = true
+	}
+
+	return append(levels, sortedKeys(custom)...), nil
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeCandidates(ctx context.Context, w io.Writer, kind, namespace string) error {
+	var candidates []string
+	var err error
+
+	switch kind {
+	case namespaceCandidates:
+		candidates, err = getNamespaces(ctx)
+	case identifierCandidates:
+		candidates, err = getIdentifiers(ctx, namespace)
+	case levelCandidates:
+		candidates, err = getLevels(ctx)
+	default:
+		return fmt.Errorf("unknown completion candidates: %q", kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i := range candidates {
+		if _, err := fmt.Fprintln(w, candidates[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Complete prints, one per line, the values the shell can offer for a flag.
+// It is invoked by the generated completion scripts.
+func Complete(ctx context.Context, args []string) error {
+	doc := `Usage:
+  helper completion __complete namespace
+  helper completion __complete identifier [<namespace>]
+  helper completion __complete level
+Options:
+  -h --help             Show this screen.
+
+Description:
+  The completion __complete command lists values for --namespace, --identifier and --level.
+  It is invoked by the shell completion scripts.
+`
+	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand",
+			strings.Join(args, " "),
+		)
+	}
+	if len(parsedArgs) == 0 {
+		return nil
+	}
+
+	kind := namespaceCandidates
+	if parsedArgs[identifierCandidates].(bool) {
+		kind = identifierCandidates
+	} else if parsedArgs[levelCandidates].(bool) {
+		kind = levelCandidates
+	}
+
+	namespace := ""
+	if passedNamespace := parsedArgs["<namespace>"]; passedNamespace != nil {
+		namespace = passedNamespace.(string)
+	}
+
+	return writeCandidates(ctx, os.Stdout, kind, namespace)
+}
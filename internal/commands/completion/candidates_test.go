@@ -0,0 +1,114 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/completion"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("Candidates", func() {
+	BeforeEach(func() {
+		dc := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: v1alpha1.Component{Namespace: "projectsveltos", Identifier: "SveltosManager"},
+						LogLevel: v1alpha1.LogLevelDebug},
+					{Component: v1alpha1.Component{Namespace: "projectsveltos", Identifier: "ClassifierManager"},
+						LogLevel: v1alpha1.LogLevelInfo},
+					{Component: v1alpha1.Component{Namespace: "capi", Identifier: "capi-controller"},
+						LogLevel: v1alpha1.LogLevelVerbose},
+				},
+			},
+		}
+
+		initObjects := []client.Object{
+			dc,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("lists namespaces from LogSetting entries and cluster", func() {
+		var buf bytes.Buffer
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "namespace", "")).To(Succeed())
+		Expect(strings.Fields(buf.String())).To(Equal([]string{"capi", "kube-system", "projectsveltos"}))
+	})
+
+	It("lists identifiers in a given namespace", func() {
+		var buf bytes.Buffer
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "identifier", "projectsveltos")).To(Succeed())
+		Expect(strings.Fields(buf.String())).To(Equal([]string{"ClassifierManager", "SveltosManager"}))
+
+		buf.Reset()
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "identifier", "")).To(Succeed())
+		Expect(strings.Fields(buf.String())).To(Equal([]string{"ClassifierManager", "SveltosManager", "capi-controller"}))
+	})
+
+	It("lists built-in and custom log levels", func() {
+		dc, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		dc.Spec.CustomLevels = []v1alpha1.CustomLevel{
+			{Name: "wire", Verbosity: 15},
+			{Name: "trace", Verbosity: 12},
+		}
+		Expect(utils.GetAccessInstance().UpdateLogSetting(context.TODO(), dc)).To(Succeed())
+
+		var buf bytes.Buffer
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "level", "")).To(Succeed())
+		Expect(strings.Fields(buf.String())).To(Equal(
+			[]string{"error", "warning", "info", "debug", "verbose", "trace", "wire"}))
+	})
+
+	It("lists built-in log levels if LogSetting cannot be read", func() {
+		// LogSetting type is not known to client
+		c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+		utils.InitalizeManagementClusterAcces(runtime.NewScheme(), nil, nil, c)
+
+		var buf bytes.Buffer
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "level", "")).To(Succeed())
+		Expect(strings.Fields(buf.String())).To(Equal(
+			[]string{"error", "warning", "info", "debug", "verbose"}))
+	})
+
+	It("returns an error for unknown candidates", func() {
+		var buf bytes.Buffer
+		Expect(completion.WriteCandidates(context.TODO(), &buf, "pod", "")).ToNot(Succeed())
+	})
+})
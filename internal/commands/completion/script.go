@@ -0,0 +1,299 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	docopt "github.com/docopt/docopt-go"
+)
+
+const (
+	bash = "bash"
+	zsh  = "zsh"
+	fish = "fish"
+)
+
+var (
+	// commands are the helper top level commands
+	commands = []string{"log-level", "completion"}
+
+	// logLevelCommands are the subcommands of helper log-level
+	logLevelCommands = []string{"show", "set", "unset", "tui", "history", "rollback"}
+
+	// shells are the shells a completion script can be generated for
+	shells = []string{bash, zsh, fish}
+
+	// dryRunModes are the values accepted by --dry-run
+	dryRunModes = []string{"none", "client", "server"}
+)
+
+const bashTemplate = `# bash completion for helper
+# Load it in the current shell with: source <(helper completion bash)
+
+__helper_namespace() {
+    local i
+    for (( i=1; i < ${#COMP_WORDS[@]}; i++ )); do
+        if [[ "${COMP_WORDS[i]}" == "--namespace" && "${COMP_WORDS[i+1]}" == "=" ]]; then
+            echo "${COMP_WORDS[i+2]}"
+            return
+        fi
+        if [[ "${COMP_WORDS[i]}" == --namespace=* ]]; then
+            echo "${COMP_WORDS[i]#--namespace=}"
+            return
+        fi
+    done
+}
+
+_helper() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    # '=' is a word break: "--namespace=foo" is split in "--namespace" "=" "foo"
+    if [[ "${cur}" == "=" ]]; then
+        cur=""
+    elif [[ "${prev}" == "=" ]]; then
+        prev="${COMP_WORDS[COMP_CWORD-2]}"
+    fi
+
+    case "${prev}" in
+    --namespace)
+        COMPREPLY=( $(compgen -W "$(helper completion __complete namespace 2>/dev/null)" -- "${cur}") )
+        return
+        ;;
+    --identifier)
+        COMPREPLY=( $(compgen -W "$(helper completion __complete identifier $(__helper_namespace) 2>/dev/null)" -- "${cur}") )
+        return
+        ;;
+    --level)
+        COMPREPLY=( $(compgen -W "$(helper completion __complete level 2>/dev/null)" -- "${cur}") )
+        return
+        ;;
+    --dry-run)
+        COMPREPLY=( $(compgen -W "{{ join .DryRunModes }}" -- "${cur}") )
+        return
+        ;;
+    esac
+
+    case "${COMP_CWORD}" in
+    1)
+        COMPREPLY=( $(compgen -W "{{ join .Commands }}" -- "${cur}") )
+        return
+        ;;
+    2)
+        case "${COMP_WORDS[1]}" in
+        log-level)
+            COMPREPLY=( $(compgen -W "{{ join .LogLevelCommands }}" -- "${cur}") )
+            ;;
+        completion)
+            COMPREPLY=( $(compgen -W "{{ join .Shells }}" -- "${cur}") )
+            ;;
+        esac
+        return
+        ;;
+    esac
+
+    if [[ "${COMP_WORDS[1]}" != "log-level" ]]; then
+        return
+    fi
+
+    case "${COMP_WORDS[2]}" in
+    show)
+        COMPREPLY=( $(compgen -W "--resolve= --tags= --node= --pod=" -- "${cur}") )
+        ;;
+    set)
+        COMPREPLY=( $(compgen -W "--namespace= --identifier= --pod= --level= --dry-run=" -- "${cur}") )
+        ;;
+    unset)
+        COMPREPLY=( $(compgen -W "--namespace= --identifier= --pod= --dry-run=" -- "${cur}") )
+        ;;
+    rollback)
+        COMPREPLY=( $(compgen -W "--to= --dry-run=" -- "${cur}") )
+        ;;
+    esac
+    if [[ "${COMPREPLY[0]}" == *= ]]; then
+        compopt -o nospace 2>/dev/null
+    fi
+}
+
+complete -F _helper helper
+`
+
+const zshTemplate = `#compdef helper
+# zsh completion for helper
+# Load it in the current shell with: source <(helper completion zsh)
+
+_helper() {
+    if compset -P '--namespace='; then
+        compadd -- ${(f)"$(helper completion __complete namespace 2>/dev/null)"}
+        return
+    fi
+    if compset -P '--identifier='; then
+        local namespace=${${(M)words:#--namespace=*}[1]#--namespace=}
+        compadd -- ${(f)"$(helper completion __complete identifier ${namespace} 2>/dev/null)"}
+        return
+    fi
+    if compset -P '--level='; then
+        compadd -- ${(f)"$(helper completion __complete level 2>/dev/null)"}
+        return
+    fi
+    if compset -P '--dry-run='; then
+        compadd -- {{ join .DryRunModes }}
+        return
+    fi
+
+    case ${CURRENT} in
+    2)
+        compadd -- {{ join .Commands }}
+        return
+        ;;
+    3)
+        case ${words[2]} in
+        log-level)
+            compadd -- {{ join .LogLevelCommands }}
+            ;;
+        completion)
+            compadd -- {{ join .Shells }}
+            ;;
+        esac
+        return
+        ;;
+    esac
+
+    if [[ ${words[2]} != log-level ]]; then
+        return
+    fi
+
+    case ${words[3]} in
+    show)
+        compadd -S '' -- --resolve= --tags= --node= --pod=
+        ;;
+    set)
+        compadd -S '' -- --namespace= --identifier= --pod= --level= --dry-run=
+        ;;
+    unset)
+        compadd -S '' -- --namespace= --identifier= --pod= --dry-run=
+        ;;
+    rollback)
+        compadd -S '' -- --to= --dry-run=
+        ;;
+    esac
+}
+
+if [[ "$funcstack[1]" == "_helper" ]]; then
+    _helper "$@"
+else
+    compdef _helper helper
+fi
+`
+
+const fishTemplate = `# fish completion for helper
+# Load it in the current shell with: helper completion fish | source
+
+function __helper_namespace
+    for token in (commandline -opc)
+        switch $token
+            case '--namespace=*'
+                string replace -- '--namespace=' '' $token
+                return
+        end
+    end
+end
+
+complete -c helper -f
+complete -c helper -n '__fish_use_subcommand' -a '{{ join .Commands }}'
+complete -c helper -n '__fish_seen_subcommand_from log-level; and not __fish_seen_subcommand_from {{ join .LogLevelCommands }}' -a '{{ join .LogLevelCommands }}'
+complete -c helper -n '__fish_seen_subcommand_from completion' -a '{{ join .Shells }}'
+complete -c helper -n '__fish_seen_subcommand_from set unset' -l namespace -x -a '(helper completion __complete namespace 2>/dev/null)'
+complete -c helper -n '__fish_seen_subcommand_from set unset' -l identifier -x -a '(helper completion __complete identifier (__helper_namespace) 2>/dev/null)'
+complete -c helper -n '__fish_seen_subcommand_from show set unset' -l pod -x
+complete -c helper -n '__fish_seen_subcommand_from set unset rollback' -l dry-run -x -a '{{ join .DryRunModes }}'
+complete -c helper -n '__fish_seen_subcommand_from rollback' -l to -x
+complete -c helper -n '__fish_seen_subcommand_from show' -l resolve -x
+complete -c helper -n '__fish_seen_subcommand_from show' -l tags -x
+complete -c helper -n '__fish_seen_subcommand_from show' -l node -x
+complete -c helper -n '__fish_seen_subcommand_from set' -l level -x -a '(helper completion __complete level 2>/dev/null)'
+`
+
+func writeScript(w io.Writer, shell string) error {
+	var text string
+	switch shell {
+	case bash:
+		text = bashTemplate
+	case zsh:
+		text = zshTemplate
+	case fish:
+		text = fishTemplate
+	default:
+		return fmt.Errorf("unsupported shell: %q", shell)
+	}
+
+	funcs := template.FuncMap{
+		"join": func(s []string) string { return strings.Join(s, " ") },
+	}
+
+	tmpl, err := template.New(shell).Funcs(funcs).Parse(text)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(w, struct {
+		Commands         []string
+		LogLevelCommands []string
+		Shells           []string
+		DryRunModes      []string
+	}{
+		Commands:         commands,
+		LogLevelCommands: logLevelCommands,
+		Shells:           shells,
+		DryRunModes:      dryRunModes,
+	})
+}
+
+// Script prints the completion script for the given shell
+func Script(ctx context.Context, args []string, shell string) error {
+	doc := `Usage:
+  helper completion (bash|zsh|fish)
+Options:
+  -h --help             Show this screen.
+
+Description:
+  The completion command prints a script which enables shell completion for helper.
+  Values for --namespace, --identifier and --level are fetched from the cluster while typing.
+
+  bash: source <(helper completion bash)
+  zsh:  source <(helper completion zsh)
+  fish: helper completion fish | source
+`
+	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand",
+			strings.Join(args, " "),
+		)
+	}
+	if len(parsedArgs) == 0 {
+		return nil
+	}
+
+	return writeScript(os.Stdout, shell)
+}
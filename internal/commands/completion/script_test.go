@@ -0,0 +1,59 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package completion_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/gianlucam76/pod-log-level/internal/commands/completion"
+)
+
+var _ = Describe("Script", func() {
+	It("generates completion scripts querying the cluster", func() {
+		for _, shell := range []string{"bash", "zsh", "fish"} {
+			var buf bytes.Buffer
+			Expect(completion.WriteScript(&buf, shell)).To(Succeed())
+			script := buf.String()
+			Expect(script).To(ContainSubstring("helper completion __complete namespace"))
+			Expect(script).To(ContainSubstring("helper completion __complete identifier"))
+			Expect(script).To(ContainSubstring("show set unset tui history rollback"))
+		}
+	})
+
+	It("completes log levels", func() {
+		for _, shell := range []string{"bash", "zsh", "fish"} {
+			var buf bytes.Buffer
+			Expect(completion.WriteScript(&buf, shell)).To(Succeed())
+			Expect(buf.String()).To(ContainSubstring("helper completion __complete level"))
+		}
+
+		var buf bytes.Buffer
+		Expect(completion.WriteScript(&buf, "bash")).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("--level="))
+		Expect(buf.String()).To(ContainSubstring("--pod="))
+		Expect(buf.String()).To(ContainSubstring("--resolve="))
+		Expect(buf.String()).To(ContainSubstring("none client server"))
+	})
+
+	It("returns an error for unsupported shells", func() {
+		var buf bytes.Buffer
+		Expect(completion.WriteScript(&buf, "powershell")).ToNot(Succeed())
+	})
+})
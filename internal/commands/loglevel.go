@@ -38,6 +38,9 @@ func LogLevel(ctx context.Context, args []string, logger logr.Logger) error {
 	show          Show current log severity configuration.
 	set           Set log severity.
 	unset         Remove log severity setting for a given component.
+	tui           Browse and change log severity interactively.
+	history       Show most recent log severity configurations.
+	rollback      Restore log severity configuration of a previous revision.
 
 Options:
 	-h --help      Show this screen.
@@ -74,6 +77,12 @@ Description:
 		return loglevel.Set(ctx, arguments)
 	case "unset":
 		return loglevel.Unset(ctx, arguments)
+	case "tui":
+		return loglevel.Tui(ctx, arguments)
+	case "history":
+		return loglevel.History(ctx, arguments)
+	case "rollback":
+		return loglevel.Rollback(ctx, arguments)
 	default:
 		//nolint: forbidigo // print doc
 		fmt.Println(doc)
@@ -0,0 +1,104 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/loglevel"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("DryRun", func() {
+	component1 := v1alpha1.Component{Namespace: "web", Identifier: "frontend"}
+	component2 := v1alpha1.Component{Namespace: "web", Identifier: "backend"}
+
+	BeforeEach(func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component1, LogLevel: v1alpha1.LogLevelInfo},
+		}
+
+		initObjects := []client.Object{dc}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("client dry-run prints resulting LogSetting without changing it", func() {
+		old := os.Stdout // keep backup of the real stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component2, loglevel.DryRunClient)).To(Succeed())
+
+		w.Close()
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, r)
+		Expect(err).To(BeNil())
+		os.Stdout = old
+
+		printed := &v1alpha1.LogSetting{}
+		Expect(yaml.Unmarshal(buf.Bytes(), printed)).To(Succeed())
+		Expect(printed.Kind).To(Equal("LogSetting"))
+		Expect(printed.Spec.Configuration).To(ConsistOf(
+			v1alpha1.ComponentConfiguration{Component: component1, LogLevel: v1alpha1.LogLevelInfo},
+			v1alpha1.ComponentConfiguration{Component: component2, LogLevel: v1alpha1.LogLevelDebug},
+		))
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Spec.Configuration)).To(Equal(1))
+	})
+
+	It("server dry-run does not persist changes", func() {
+		Expect(loglevel.UnsetLogSetting(context.TODO(), component1, loglevel.DryRunServer)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Spec.Configuration)).To(Equal(1))
+		Expect(currentDC.Spec.Configuration[0].Component).To(Equal(component1))
+	})
+
+	It("server dry-run does not create LogSetting", func() {
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelVerbose,
+			component2, loglevel.DryRunServer)).To(Succeed())
+
+		_, err = utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
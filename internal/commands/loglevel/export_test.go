@@ -16,8 +16,45 @@ limitations under the License.
 
 package loglevel
 
+import (
+	"context"
+	"io"
+)
+
 var (
-	ShowLogSetting   = showLogSetting
-	UpdateLogSetting = updateLogSetting
-	UnsetLogSetting  = unsetLogSetting
+	ShowLogSetting    = showLogSetting
+	UpdateLogSetting  = updateLogSetting
+	UnsetLogSetting   = unsetLogSetting
+	ParseLevel        = parseLevel
+	ParsePod          = parsePod
+	ParseComponent    = parseComponent
+	ParseTags         = parseTags
+	ResolveLogSetting = resolveLogSetting
+	SetPodLogLevel    = setPodLogLevel
+
+	ShowHistory        = showHistory
+	RollbackLogSetting = rollbackLogSetting
+	RecordRevision     = recordRevision
+)
+
+const DefaultRevisionHistoryLimit = defaultRevisionHistoryLimit
+
+const (
+	DryRunNone   = dryRunNone
+	DryRunClient = dryRunClient
+	DryRunServer = dryRunServer
 )
+
+type LogLevelTUI = logLevelTUI
+
+func (t *logLevelTUI) Reload(ctx context.Context) error {
+	return t.reload(ctx)
+}
+
+func (t *logLevelTUI) HandleKey(ctx context.Context, key string) (bool, error) {
+	return t.handleKey(ctx, key)
+}
+
+func (t *logLevelTUI) Render(w io.Writer) error {
+	return t.render(w)
+}
@@ -0,0 +1,226 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	docopt "github.com/docopt/docopt-go"
+	"github.com/olekukonko/tablewriter"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+const (
+	// defaultRevisionHistoryLimit is the number of revisions kept in LogSetting
+	// Status when Spec RevisionHistoryLimit is not set
+	defaultRevisionHistoryLimit = 10
+)
+
+// getAuthor returns the user of the current kubeconfig context. If that is
+// not available, the OS user is returned.
+func getAuthor() string {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{}).RawConfig()
+	if err == nil {
+		if kubeContext, ok := config.Contexts[config.CurrentContext]; ok && kubeContext.AuthInfo != "" {
+			return kubeContext.AuthInfo
+		}
+	}
+
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	return "unknown"
+}
+
+// recordRevision adds a snapshot of current LogSetting configuration to its
+// revisions. Only the most recent Spec RevisionHistoryLimit are kept.
+func recordRevision(dc *v1alpha1.LogSetting, author string, now metav1.Time) {
+	revision := int64(1)
+	if n := len(dc.Status.Revisions); n > 0 {
+		revision = dc.Status.Revisions[n-1].Revision + 1
+	}
+
+	configuration := make([]v1alpha1.ComponentConfiguration, len(dc.Spec.Configuration))
+	copy(configuration, dc.Spec.Configuration)
+
+	dc.Status.Revisions = append(dc.Status.Revisions, v1alpha1.Revision{
+		Revision:      revision,
+		Author:        author,
+		Time:          now,
+		Configuration: configuration,
+	})
+
+	limit := defaultRevisionHistoryLimit
+	if dc.Spec.RevisionHistoryLimit != nil {
+		limit = int(*dc.Spec.RevisionHistoryLimit)
+	}
+	if n := len(dc.Status.Revisions); n > limit {
+		dc.Status.Revisions = dc.Status.Revisions[n-limit:]
+	}
+}
+
+// isRecorded returns true if current LogSetting configuration is the one of
+// its latest revision
+func isRecorded(dc *v1alpha1.LogSetting) bool {
+	n := len(dc.Status.Revisions)
+	return n > 0 &&
+		equality.Semantic.DeepEqual(dc.Status.Revisions[n-1].Configuration, dc.Spec.Configuration)
+}
+
+func collectRevisions(ctx context.Context) ([]v1alpha1.Revision, error) {
+	instance := utils.GetAccessInstance()
+
+	dc, err := instance.GetLogSetting(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return make([]v1alpha1.Revision, 0), nil
+		}
+		return nil, err
+	}
+
+	return dc.Status.Revisions, nil
+}
+
+func formatConfiguration(configuration []v1alpha1.ComponentConfiguration) string {
+	entries := make([]string, len(configuration))
+	for i := range configuration {
+		entries[i] = fmt.Sprintf("%s=%s", entryName(&configuration[i]), configuration[i].LogLevel)
+	}
+	return strings.Join(entries, ", ")
+}
+
+func showHistory(ctx context.Context) error {
+	revisions, err := collectRevisions(ctx)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"REVISION", "AUTHOR", "TIME", "CONFIGURATION"})
+	genRow := func(revision, author, changeTime, configuration string) []string {
+		return []string{
+			revision,
+			author,
+			changeTime,
+			configuration,
+		}
+	}
+
+	for i := range revisions {
+		author := revisions[i].Author
+		if author == "" {
+			author = "(not via CLI)"
+		}
+		table.Append(genRow(strconv.FormatInt(revisions[i].Revision, 10), author,
+			revisions[i].Time.Format(time.RFC3339), formatConfiguration(revisions[i].Configuration)))
+	}
+
+	table.Render()
+	return nil
+}
+
+func rollbackLogSetting(ctx context.Context, revision int64, mode dryRun) error {
+	revisions, err := collectRevisions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range revisions {
+		if revisions[i].Revision == revision {
+			return updateLogLevelConfiguration(ctx, revisions[i].Configuration, mode)
+		}
+	}
+
+	return fmt.Errorf("revision %d not found", revision)
+}
+
+// History displays the most recent log verbosity changes
+func History(ctx context.Context, args []string) error {
+	doc := `Usage:
+  helper log-level history
+Options:
+  -h --help             Show this screen.
+
+Description:
+  The log-level history command shows the most recent log verbosity configurations.
+  Any of those can be restored using log-level rollback.
+`
+	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand",
+			strings.Join(args, " "),
+		)
+	}
+	if len(parsedArgs) == 0 {
+		return nil
+	}
+
+	return showHistory(ctx)
+}
+
+// Rollback restores log verbosity configuration of a given revision
+func Rollback(ctx context.Context, args []string) error {
+	doc := `Usage:
+  helper log-level rollback --to=<revision> [--dry-run=<mode>]
+Options:
+  -h --help                    Show this screen.
+     --to=<revision>           Revision to restore. See log-level history.
+     --dry-run=<mode>          Preview the change. With client, resulting LogSetting is printed.
+                               With server, change is validated by the API server but not persisted.
+
+Description:
+  The log-level rollback command restores log verbosity configuration of a previous revision.
+`
+	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand",
+			strings.Join(args, " "),
+		)
+	}
+	if len(parsedArgs) == 0 {
+		return nil
+	}
+
+	revision, err := strconv.ParseInt(parsedArgs["--to"].(string), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid revision %q: %w", parsedArgs["--to"], err)
+	}
+
+	mode, err := parseDryRun(parsedArgs["--dry-run"])
+	if err != nil {
+		return err
+	}
+
+	return rollbackLogSetting(ctx, revision, mode)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/loglevel"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("History", func() {
+	component1 := v1alpha1.Component{Namespace: "batch", Identifier: "scheduler"}
+	component2 := v1alpha1.Component{Namespace: "batch", Identifier: "worker"}
+
+	BeforeEach(func() {
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("every change records a revision which can be restored", func() {
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component1, loglevel.DryRunNone)).To(Succeed())
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelVerbose,
+			component2, loglevel.DryRunNone)).To(Succeed())
+		Expect(loglevel.UnsetLogSetting(context.TODO(), component1, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Status.Revisions)).To(Equal(3))
+		for i := range currentDC.Status.Revisions {
+			Expect(currentDC.Status.Revisions[i].Revision).To(Equal(int64(i + 1)))
+			Expect(currentDC.Status.Revisions[i].Author).ToNot(BeEmpty())
+		}
+		Expect(currentDC.Status.Revisions[2].Configuration).To(Equal(currentDC.Spec.Configuration))
+
+		Expect(loglevel.RollbackLogSetting(context.TODO(), 2, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err = utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(ConsistOf(
+			v1alpha1.ComponentConfiguration{Component: component1, LogLevel: v1alpha1.LogLevelDebug},
+			v1alpha1.ComponentConfiguration{Component: component2, LogLevel: v1alpha1.LogLevelVerbose},
+		))
+		Expect(len(currentDC.Status.Revisions)).To(Equal(4))
+
+		Expect(loglevel.RollbackLogSetting(context.TODO(), 100, loglevel.DryRunNone)).ToNot(Succeed())
+	})
+
+	It("history displays revisions", func() {
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component1, loglevel.DryRunNone)).To(Succeed())
+
+		old := os.Stdout // keep backup of the real stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		Expect(loglevel.ShowHistory(context.TODO())).To(Succeed())
+
+		w.Close()
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, r)
+		Expect(err).To(BeNil())
+		os.Stdout = old
+
+		found := false
+		lines := strings.Split(buf.String(), "\n")
+		for i := range lines {
+			if strings.Contains(lines[i], "batch/scheduler=LogLevelDebug") {
+				found = true
+				break
+			}
+		}
+		Expect(found).To(BeTrue())
+	})
+
+	It("only most recent revisions are kept", func() {
+		dc := getLogSetting()
+		for i := 0; i < loglevel.DefaultRevisionHistoryLimit+2; i++ {
+			loglevel.RecordRevision(dc, "admin", metav1.Now())
+		}
+
+		Expect(len(dc.Status.Revisions)).To(Equal(loglevel.DefaultRevisionHistoryLimit))
+		Expect(dc.Status.Revisions[0].Revision).To(Equal(int64(3)))
+		Expect(dc.Status.Revisions[loglevel.DefaultRevisionHistoryLimit-1].Revision).To(Equal(int64(loglevel.DefaultRevisionHistoryLimit + 2)))
+	})
+
+	It("records configuration set outside CLI before changing it", func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component1, LogLevel: v1alpha1.LogLevelInfo},
+		}
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelVerbose,
+			component1, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Status.Revisions)).To(Equal(2))
+		Expect(currentDC.Status.Revisions[0].Author).To(BeEmpty())
+		Expect(currentDC.Status.Revisions[0].Configuration).To(Equal(dc.Spec.Configuration))
+
+		// Configuration edited outside CLI is recorded as well
+		currentDC.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component2, LogLevel: v1alpha1.LogLevelDebug},
+		}
+		Expect(c.Update(context.TODO(), currentDC)).To(Succeed())
+		Expect(loglevel.UnsetLogSetting(context.TODO(), component2, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err = utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Status.Revisions)).To(Equal(4))
+		Expect(currentDC.Status.Revisions[2].Author).To(BeEmpty())
+		Expect(currentDC.Status.Revisions[2].Configuration).To(ConsistOf(
+			v1alpha1.ComponentConfiguration{Component: component2, LogLevel: v1alpha1.LogLevelDebug},
+		))
+
+		// Configuration existing before first CLI change can be restored
+		Expect(loglevel.RollbackLogSetting(context.TODO(), 1, loglevel.DryRunNone)).To(Succeed())
+		currentDC, err = utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(Equal(dc.Spec.Configuration))
+	})
+
+	It("keeps as many revisions as RevisionHistoryLimit", func() {
+		limit := int32(3)
+		dc := getLogSetting()
+		dc.Spec.RevisionHistoryLimit = &limit
+		for i := 0; i < 5; i++ {
+			loglevel.RecordRevision(dc, "admin", metav1.Now())
+		}
+
+		Expect(len(dc.Status.Revisions)).To(Equal(3))
+		Expect(dc.Status.Revisions[0].Revision).To(Equal(int64(3)))
+	})
+})
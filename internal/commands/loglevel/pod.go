@@ -0,0 +1,74 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+// parsePod parses a Pod reference in the <namespace>/<name> format
+func parsePod(value string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid pod %q: expected <namespace>/<name>", value)
+	}
+	return namespace, name, nil
+}
+
+// setPodLogLevel sets, on Pod namespace/name, the annotation overriding the log
+// severity of its component. With LogLevelNotSet the annotation is removed.
+func setPodLogLevel(ctx context.Context, namespace, name string, logSeverity v1alpha1.LogLevel,
+	mode dryRun) error {
+
+	instance := utils.GetAccessInstance()
+
+	pod, err := instance.GetPod(ctx, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFrom(pod.DeepCopy())
+	if logSeverity == v1alpha1.LogLevelNotSet {
+		if _, ok := pod.Annotations[v1alpha1.PodLogLevelAnnotation]; !ok {
+			return nil
+		}
+		delete(pod.Annotations, v1alpha1.PodLogLevelAnnotation)
+	} else {
+		if pod.Annotations == nil {
+			pod.Annotations = make(map[string]string)
+		}
+		pod.Annotations[v1alpha1.PodLogLevelAnnotation] = string(logSeverity)
+	}
+
+	switch mode {
+	case dryRunClient:
+		pod.APIVersion = "v1"
+		pod.Kind = "Pod"
+		return printObject(pod)
+	case dryRunServer:
+		return instance.PatchPod(ctx, pod, patch, client.DryRunAll)
+	default:
+		return instance.PatchPod(ctx, pod, patch)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/loglevel"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("Pod", func() {
+	const (
+		namespace = "web"
+		name      = "frontend-6d4cf56db6-x2v9k"
+	)
+
+	BeforeEach(func() {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        name,
+				Annotations: map[string]string{"owner": "web-team"},
+			},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("parsePod accepts only <namespace>/<name>", func() {
+		podNamespace, podName, err := loglevel.ParsePod(namespace + "/" + name)
+		Expect(err).To(BeNil())
+		Expect(podNamespace).To(Equal(namespace))
+		Expect(podName).To(Equal(name))
+
+		for _, value := range []string{name, "/" + name, namespace + "/", "a/b/c"} {
+			_, _, err = loglevel.ParsePod(value)
+			Expect(err).ToNot(BeNil())
+		}
+	})
+
+	It("sets and removes the log level annotation", func() {
+		Expect(loglevel.SetPodLogLevel(context.TODO(), namespace, name, v1alpha1.LogLevelDebug,
+			loglevel.DryRunNone)).To(Succeed())
+
+		pod, err := utils.GetAccessInstance().GetPod(context.TODO(), namespace, name)
+		Expect(err).To(BeNil())
+		Expect(pod.Annotations).To(HaveKeyWithValue(v1alpha1.PodLogLevelAnnotation, string(v1alpha1.LogLevelDebug)))
+		Expect(pod.Annotations).To(HaveKeyWithValue("owner", "web-team"))
+
+		Expect(loglevel.SetPodLogLevel(context.TODO(), namespace, name, v1alpha1.LogLevelNotSet,
+			loglevel.DryRunNone)).To(Succeed())
+
+		pod, err = utils.GetAccessInstance().GetPod(context.TODO(), namespace, name)
+		Expect(err).To(BeNil())
+		Expect(pod.Annotations).ToNot(HaveKey(v1alpha1.PodLogLevelAnnotation))
+		Expect(pod.Annotations).To(HaveKeyWithValue("owner", "web-team"))
+	})
+
+	It("client dry-run prints resulting Pod without changing it", func() {
+		old := os.Stdout // keep backup of the real stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		Expect(loglevel.SetPodLogLevel(context.TODO(), namespace, name, v1alpha1.LogLevelVerbose,
+			loglevel.DryRunClient)).To(Succeed())
+
+		w.Close()
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, r)
+		Expect(err).To(BeNil())
+		os.Stdout = old
+
+		printed := &corev1.Pod{}
+		Expect(yaml.Unmarshal(buf.Bytes(), printed)).To(Succeed())
+		Expect(printed.Kind).To(Equal("Pod"))
+		Expect(printed.Annotations).To(HaveKeyWithValue(v1alpha1.PodLogLevelAnnotation, string(v1alpha1.LogLevelVerbose)))
+
+		pod, err := utils.GetAccessInstance().GetPod(context.TODO(), namespace, name)
+		Expect(err).To(BeNil())
+		Expect(pod.Annotations).ToNot(HaveKey(v1alpha1.PodLogLevelAnnotation))
+	})
+
+	It("fails when Pod does not exist", func() {
+		Expect(loglevel.SetPodLogLevel(context.TODO(), namespace, "missing", v1alpha1.LogLevelDebug,
+			loglevel.DryRunNone)).ToNot(Succeed())
+	})
+})
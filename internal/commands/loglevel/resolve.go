@@ -0,0 +1,143 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+// parseComponent parses a component reference in the <namespace>/<identifier> format
+func parseComponent(value string) (v1alpha1.Component, error) {
+	namespace, identifier, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || identifier == "" || strings.Contains(identifier, "/") {
+		return v1alpha1.Component{}, fmt.Errorf("invalid component %q: expected <namespace>/<identifier>", value)
+	}
+	return v1alpha1.Component{Namespace: namespace, Identifier: identifier}, nil
+}
+
+// parseTags parses tags in the key1=value1,key2=value2 format
+func parseTags(value string) (map[string]string, error) {
+	tags, err := labels.ConvertSelectorToLabelsMap(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tags %q: expected <key>=<value>[,<key>=<value>]", value)
+	}
+	return tags, nil
+}
+
+// resolveLogSetting writes which LogSetting entries match target, from the one
+// which applies to the least specific one. Labels of target Node, if any, are
+// fetched.
+func resolveLogSetting(ctx context.Context, w io.Writer, target *lib.Target) error {
+
+	instance := utils.GetAccessInstance()
+
+	dc, err := instance.GetLogSetting(ctx)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		dc = &v1alpha1.LogSetting{}
+	}
+
+	component := target.Component
+	if target.Node != "" {
+		n, err := instance.GetNode(ctx, target.Node)
+		if err != nil {
+			return err
+		}
+		target.NodeLabels = n.Labels
+	}
+	matches := lib.Resolve(dc, target)
+	if len(matches) == 0 {
+		_, err = fmt.Fprintf(w, "No entry matches %s/%s: default log severity applies\n",
+			component.Namespace, component.Identifier)
+		return err
+	}
+
+	// Same as components, skip entries not currently scheduled or without a
+	// valid log level
+	now := time.Now()
+	applied := -1
+	scheduled := make([]string, len(matches))
+	for i, m := range matches {
+		c := &dc.Spec.Configuration[m.Index]
+		active := true
+		if c.Schedule != nil {
+			active, err = lib.IsScheduled(c.Schedule, now)
+			switch {
+			case err != nil:
+				scheduled[i] = "invalid"
+			case active:
+				scheduled[i] = "active"
+			default:
+				scheduled[i] = "inactive"
+			}
+		}
+		if applied == -1 && active && lib.IsValidLogLevel(&dc.Spec, lib.EntryLogLevel(c)) {
+			applied = i
+		}
+	}
+
+	if applied == -1 {
+		_, err = fmt.Fprintf(w, "No entry currently applies to %s/%s: default log severity applies\n",
+			component.Namespace, component.Identifier)
+	} else {
+		c := &dc.Spec.Configuration[matches[applied].Index]
+		_, err = fmt.Fprintf(w, "Entry %s (%s match) applies to %s/%s\n", entryName(c), matches[applied].Kind,
+			component.Namespace, component.Identifier)
+	}
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"", "ENTRY", "MATCH", "VERBOSITY", "V", "SCHEDULED"})
+	for i, m := range matches {
+		c := &dc.Spec.Configuration[m.Index]
+		marker := ""
+		if i == applied {
+			marker = ">"
+		}
+		kind := string(m.Kind)
+		if m.NodeScoped {
+			kind += ", node"
+		}
+		if m.ReplicaScoped {
+			kind += ", replicas"
+		}
+		table.Append([]string{marker, entryName(c), kind, string(lib.EntryLogLevel(c)),
+			resolveVerbosity(&dc.Spec, c), scheduled[i]})
+	}
+	table.Render()
+
+	if applied > 0 {
+		_, err = fmt.Fprintln(w, "More specific entries are skipped: not scheduled now or without a valid log level")
+	}
+	return err
+}
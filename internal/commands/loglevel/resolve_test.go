@@ -0,0 +1,215 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/loglevel"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Resolve", func() {
+	component := v1alpha1.Component{Namespace: "projectsveltos", Identifier: "capi-controller"}
+
+	BeforeEach(func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{
+				Component: v1alpha1.Component{Namespace: "*", Identifier: "*"},
+				LogLevel:  v1alpha1.LogLevelInfo,
+			},
+			{
+				Component: v1alpha1.Component{Namespace: component.Namespace, Identifier: "capi-*"},
+				LogLevel:  v1alpha1.LogLevelDebug,
+			},
+			{
+				TagSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				LogLevel:    v1alpha1.LogLevelVerbose,
+			},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("parseComponent accepts only <namespace>/<identifier>", func() {
+		parsed, err := loglevel.ParseComponent("projectsveltos/capi-controller")
+		Expect(err).To(BeNil())
+		Expect(parsed).To(Equal(component))
+
+		for _, value := range []string{"capi-controller", "/capi-controller", "projectsveltos/", "a/b/c"} {
+			_, err = loglevel.ParseComponent(value)
+			Expect(err).ToNot(BeNil())
+		}
+	})
+
+	It("parseTags accepts only <key>=<value> pairs", func() {
+		tags, err := loglevel.ParseTags("team=payments,tier=controller")
+		Expect(err).To(BeNil())
+		Expect(tags).To(Equal(map[string]string{"team": "payments", "tier": "controller"}))
+
+		_, err = loglevel.ParseTags("team")
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("explains which entry applies to a component", func() {
+		var buf bytes.Buffer
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf, &lib.Target{Component: component})).To(Succeed())
+
+		lines := strings.Split(buf.String(), "\n")
+		Expect(lines[0]).To(ContainSubstring("projectsveltos/capi-* (namespace match) applies"))
+		Expect(buf.String()).To(ContainSubstring("*/*"))
+		Expect(buf.String()).ToNot(ContainSubstring("tags(team=payments)"))
+
+		// Tag entry matches as well, and it is more specific than a pattern
+		buf.Reset()
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf,
+			&lib.Target{Component: component, Tags: map[string]string{"team": "payments"}})).To(Succeed())
+		lines = strings.Split(buf.String(), "\n")
+		Expect(lines[0]).To(ContainSubstring("tags(team=payments) (tags match) applies"))
+		Expect(strings.Index(buf.String(), "tags(team=payments)")).To(
+			BeNumerically("<", strings.Index(buf.String(), "projectsveltos/capi-*")))
+	})
+
+	It("falls back to the cluster-wide default", func() {
+		var buf bytes.Buffer
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf,
+			&lib.Target{Component: v1alpha1.Component{Namespace: "kube-system", Identifier: "coredns"}})).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("*/* (cluster match) applies"))
+	})
+
+	It("considers entries limited to the node component runs on", func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{
+				Component:    component,
+				NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "eu-west-1a"}},
+				LogLevel:     v1alpha1.LogLevelVerbose,
+			},
+			{Component: component, LogLevel: v1alpha1.LogLevelInfo},
+		}
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "worker-3",
+				Labels: map[string]string{"zone": "eu-west-1a"},
+			},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc, node).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		var buf bytes.Buffer
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf,
+			&lib.Target{Component: component, Node: node.Name})).To(Succeed())
+		Expect(strings.Split(buf.String(), "\n")[0]).To(ContainSubstring("[node labels: zone=eu-west-1a]"))
+		Expect(buf.String()).To(ContainSubstring("component, node"))
+
+		// Without node, entry limited to nodes does not match
+		buf.Reset()
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf, &lib.Target{Component: component})).To(Succeed())
+		Expect(buf.String()).ToNot(ContainSubstring("zone=eu-west-1a"))
+
+		// Setting the component never changes entries limited to nodes
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component, loglevel.DryRunNone)).To(Succeed())
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelVerbose))
+		Expect(currentDC.Spec.Configuration[1].LogLevel).To(Equal(v1alpha1.LogLevelDebug))
+	})
+
+	It("considers entries limited to a percentage of replicas only when Pod is known", func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component, ReplicaPercentage: pointer.Int32(100), LogLevel: v1alpha1.LogLevelVerbose},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		var buf bytes.Buffer
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf,
+			&lib.Target{Component: component, Replica: "capi-controller-6d4cf56db6-x2v9k"})).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("[replicas: 100%]"))
+		Expect(buf.String()).To(ContainSubstring("component, replicas"))
+
+		buf.Reset()
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf, &lib.Target{Component: component})).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring("No entry matches"))
+	})
+
+	It("skips entries not scheduled or without a valid log level", func() {
+		now := time.Now().UTC()
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component, LogLevel: v1alpha1.LogLevelNotSet},
+			{
+				Component: v1alpha1.Component{Namespace: component.Namespace, Identifier: "capi-*"},
+				LogLevel:  v1alpha1.LogLevelVerbose,
+				Schedule: &v1alpha1.Schedule{
+					Start: now.Add(2 * time.Hour).Format("15:04"),
+					End:   now.Add(3 * time.Hour).Format("15:04"),
+				},
+			},
+			{
+				Component: v1alpha1.Component{Namespace: component.Namespace, Identifier: "*"},
+				LogLevel:  v1alpha1.LogLevelDebug,
+				Schedule: &v1alpha1.Schedule{
+					Start: now.Add(-time.Hour).Format("15:04"),
+					End:   now.Add(time.Hour).Format("15:04"),
+				},
+			},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		var buf bytes.Buffer
+		Expect(loglevel.ResolveLogSetting(context.TODO(), &buf, &lib.Target{Component: component})).To(Succeed())
+		lines := strings.Split(buf.String(), "\n")
+		Expect(lines[0]).To(ContainSubstring("projectsveltos/* (namespace match) applies"))
+		Expect(buf.String()).To(ContainSubstring("inactive"))
+		Expect(buf.String()).To(ContainSubstring("More specific entries are skipped"))
+		for _, line := range lines {
+			if strings.Contains(line, ">") {
+				Expect(line).To(ContainSubstring("projectsveltos/*"))
+				Expect(line).To(ContainSubstring("active"))
+			}
+		}
+	})
+})
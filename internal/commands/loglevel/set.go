@@ -27,52 +27,61 @@ import (
 )
 
 func updateLogSetting(ctx context.Context, logSeverity v1alpha1.LogLevel,
-	component v1alpha1.Component) error {
+	component v1alpha1.Component, mode dryRun) error {
 
 	cc, err := collectLogLevelConfiguration(ctx)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	found := false
 	spec := make([]v1alpha1.ComponentConfiguration, len(cc))
 
 	for i, c := range cc {
-		if c.component.Namespace == component.Namespace &&
-			c.component.Identifier == component.Identifier {
+		spec[i] = c.spec
 
-			spec[i] = v1alpha1.ComponentConfiguration{
-				Component: c.component,
-				LogLevel:  logSeverity,
-			}
+		if c.targets(component) {
+
+			spec[i].LogLevel = logSeverity
 			found = true
-			break
 		}
 	}
 
 	if !found {
-		spec = append(spec,
-			v1alpha1.ComponentConfiguration{
-				Component: component,
-				LogLevel:  logSeverity,
-			},
-		)
+		entry := v1alpha1.ComponentConfiguration{
+			Component: component,
+			LogLevel:  logSeverity,
+		}
+		// Among entries for the same component the last one wins: the new
+		// entry goes before scheduled ones, which keep applying while scheduled
+		position := len(spec)
+		for i := range spec {
+			if spec[i].Schedule != nil && spec[i].TagSelector == nil && spec[i].Component == component {
+				position = i
+				break
+			}
+		}
+		spec = append(spec[:position], append([]v1alpha1.ComponentConfiguration{entry}, spec[position:]...)...)
 	}
 
-	return updateLogLevelConfiguration(ctx, spec)
+	return updateLogLevelConfiguration(ctx, spec, mode)
 }
 
 // Set displays/changes log verbosity for a given component
 func Set(ctx context.Context, args []string) error {
 	doc := `Usage:
-  helper log-level set --namespace=<namespace> --identifier=<identifier> (--info|--debug|--verbose)
+  helper log-level set --namespace=<namespace> --identifier=<identifier> --level=<level> [--dry-run=<mode>]
+  helper log-level set --pod=<pod> --level=<level> [--dry-run=<mode>]
 Options:
   -h --help                    Show this screen.
      --namespace=<namespace>   Namespace of the component for which log severity is being set.
      --identifier=<identifier> Identifier of the component for which log severity is being set.
-     --info                    Set log severity to info.
-     --debug                   Set log severity to debug.
-     --verbose                 Set log severity to verbose.
+     --pod=<pod>               Pod, as <namespace>/<name>, for which log severity is being set.
+                               It overrides, for that Pod only, log severity of its component.
+     --level=<level>           Log severity to set: error, warning, info, debug, verbose or
+                               a level declared in LogSetting customLevels.
+     --dry-run=<mode>          Preview the change. With client, resulting LogSetting (or Pod) is printed.
+                               With server, change is validated by the API server but not persisted.
 	 
 Description:
   The log-level set command set log severity for the specified component.
@@ -88,6 +97,24 @@ Description:
 		return nil
 	}
 
+	logSeverity, err := parseLevel(ctx, parsedArgs["--level"].(string))
+	if err != nil {
+		return err
+	}
+
+	mode, err := parseDryRun(parsedArgs["--dry-run"])
+	if err != nil {
+		return err
+	}
+
+	if passedPod := parsedArgs["--pod"]; passedPod != nil {
+		podNamespace, podName, err := parsePod(passedPod.(string))
+		if err != nil {
+			return err
+		}
+		return setPodLogLevel(ctx, podNamespace, podName, logSeverity, mode)
+	}
+
 	namespace := ""
 	if passedNamespace := parsedArgs["--namespace"]; passedNamespace != nil {
 		namespace = passedNamespace.(string)
@@ -98,18 +125,5 @@ Description:
 		identifier = passedIdentifier.(string)
 	}
 
-	info := parsedArgs["--info"].(bool)
-	debug := parsedArgs["--debug"].(bool)
-	verbose := parsedArgs["--verbose"].(bool)
-
-	var logSeverity v1alpha1.LogLevel
-	if info {
-		logSeverity = v1alpha1.LogLevelInfo
-	} else if debug {
-		logSeverity = v1alpha1.LogLevelDebug
-	} else if verbose {
-		logSeverity = v1alpha1.LogLevelVerbose
-	}
-
-	return updateLogSetting(ctx, logSeverity, v1alpha1.Component{Namespace: namespace, Identifier: identifier})
+	return updateLogSetting(ctx, logSeverity, v1alpha1.Component{Namespace: namespace, Identifier: identifier}, mode)
 }
@@ -18,9 +18,11 @@ package loglevel_test
 
 import (
 	"context"
+	"fmt"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
@@ -38,7 +40,7 @@ var _ = Describe("Set", func() {
 
 		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
 		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
-			component)).To(Succeed())
+			component, loglevel.DryRunNone)).To(Succeed())
 
 		k8sAccess := utils.GetAccessInstance()
 
@@ -51,7 +53,7 @@ var _ = Describe("Set", func() {
 		Expect(currentDC.Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelDebug))
 
 		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelInfo,
-			component)).To(Succeed())
+			component, loglevel.DryRunNone)).To(Succeed())
 		currentDC, err = k8sAccess.GetLogSetting(context.TODO())
 		Expect(err).To(BeNil())
 		Expect(currentDC).ToNot(BeNil())
@@ -60,4 +62,148 @@ var _ = Describe("Set", func() {
 		Expect(currentDC.Spec.Configuration[0].Component).To(Equal(component))
 		Expect(currentDC.Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelInfo))
 	})
+
+	It("set preserves other components settings", func() {
+		component1 := v1alpha1.Component{Namespace: "foo", Identifier: "bar"}
+		component2 := v1alpha1.Component{Namespace: "foo", Identifier: "baz"}
+
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component1, LogLevel: v1alpha1.LogLevelInfo},
+			{Component: component2, LogLevel: v1alpha1.LogLevelVerbose},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component1, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(ConsistOf(
+			v1alpha1.ComponentConfiguration{Component: component1, LogLevel: v1alpha1.LogLevelDebug},
+			v1alpha1.ComponentConfiguration{Component: component2, LogLevel: v1alpha1.LogLevelVerbose},
+		))
+	})
+
+	It("set preserves custom levels and mappings", func() {
+		component := v1alpha1.Component{Namespace: "foo", Identifier: "bar"}
+		debug := int32(4)
+
+		dc := getLogSetting()
+		dc.Spec.CustomLevels = []v1alpha1.CustomLevel{
+			{Name: "trace", Verbosity: 12},
+		}
+		dc.Spec.Mapping = &v1alpha1.VerbosityMapping{Debug: &debug}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevel("trace"),
+			component, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.CustomLevels).To(Equal(dc.Spec.CustomLevels))
+		Expect(currentDC.Spec.Mapping).To(Equal(dc.Spec.Mapping))
+		Expect(currentDC.Spec.Configuration).To(HaveLen(1))
+	})
+
+	It("set preserves entries order", func() {
+		dc := getLogSetting()
+		for i := 0; i < 30; i++ {
+			dc.Spec.Configuration = append(dc.Spec.Configuration,
+				v1alpha1.ComponentConfiguration{
+					TagSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+					LogLevel:    []v1alpha1.LogLevel{v1alpha1.LogLevelDebug, v1alpha1.LogLevelVerbose}[i%2],
+				},
+				v1alpha1.ComponentConfiguration{
+					Component: v1alpha1.Component{Namespace: "foo", Identifier: fmt.Sprintf("component-%d", 29-i)},
+					LogLevel:  v1alpha1.LogLevelInfo,
+				},
+			)
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+		component := v1alpha1.Component{Namespace: "foo", Identifier: "component-0"}
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component, loglevel.DryRunNone)).To(Succeed())
+
+		expected := dc.Spec.Configuration
+		for i := range expected {
+			if expected[i].Component == component {
+				expected[i].LogLevel = v1alpha1.LogLevelDebug
+			}
+		}
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(Equal(expected))
+	})
+
+	It("set does not change scheduled entries", func() {
+		component := v1alpha1.Component{Namespace: "foo", Identifier: "bar"}
+		scheduled := v1alpha1.ComponentConfiguration{
+			Component: component,
+			LogLevel:  v1alpha1.LogLevelVerbose,
+			Schedule:  &v1alpha1.Schedule{Start: "22:00", End: "04:00"},
+		}
+
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{scheduled}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelDebug,
+			component, loglevel.DryRunNone)).To(Succeed())
+
+		// New entry goes before the scheduled one, which still applies while scheduled
+		unscheduled := v1alpha1.ComponentConfiguration{Component: component, LogLevel: v1alpha1.LogLevelDebug}
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(Equal([]v1alpha1.ComponentConfiguration{unscheduled, scheduled}))
+
+		// Then unscheduled entry is updated
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelInfo,
+			component, loglevel.DryRunNone)).To(Succeed())
+		unscheduled.LogLevel = v1alpha1.LogLevelInfo
+		currentDC, err = utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(Equal([]v1alpha1.ComponentConfiguration{unscheduled, scheduled}))
+	})
+
+	It("parseLevel accepts built-in and declared custom levels", func() {
+		dc := getLogSetting()
+		dc.Spec.CustomLevels = []v1alpha1.CustomLevel{
+			{Name: "trace", Verbosity: 12},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		level, err := loglevel.ParseLevel(context.TODO(), "warning")
+		Expect(err).To(BeNil())
+		Expect(level).To(Equal(v1alpha1.LogLevelWarning))
+
+		level, err = loglevel.ParseLevel(context.TODO(), "trace")
+		Expect(err).To(BeNil())
+		Expect(level).To(Equal(v1alpha1.LogLevel("trace")))
+
+		_, err = loglevel.ParseLevel(context.TODO(), "wire")
+		Expect(err).ToNot(BeNil())
+	})
 })
@@ -19,34 +19,64 @@ package loglevel
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	docopt "github.com/docopt/docopt-go"
 	"github.com/olekukonko/tablewriter"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/gianlucam76/pod-log-level/lib"
 )
 
-func showLogSetting(ctx context.Context) error {
-	componentConfiguration, err := collectLogLevelConfiguration(ctx)
-	if err != nil {
-		return err
+// writeLogSettingTable writes the table with all component configurations.
+// If selected is a valid index, that row is marked.
+func writeLogSettingTable(w io.Writer, componentConfiguration []*componentConfiguration, selected int) {
+	table := tablewriter.NewWriter(w)
+	header := []string{"COMPONENT NAMESPACE", "COMPONENT IDENTIFIER", "VERBOSITY", "V"}
+	if selected >= 0 {
+		header = append([]string{""}, header...)
 	}
-
-	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"COMPONENT NAMESPACE", "COMPONENT IDENTIFIER", "VERBOSITY"})
-	genRow := func(namespace, identifier, verbosity string) []string {
+	table.SetHeader(header)
+	genRow := func(namespace, identifier, verbosity, v string) []string {
 		return []string{
 			namespace,
 			identifier,
 			verbosity,
+			v,
 		}
 	}
 
-	for _, c := range componentConfiguration {
-		table.Append(genRow(c.component.Namespace, c.component.Identifier, string(c.logSeverity)))
+	for i, c := range componentConfiguration {
+		namespace, identifier := c.component.Namespace, c.component.Identifier
+		if c.spec.TagSelector != nil {
+			namespace, identifier = "", "tags: "+metav1.FormatLabelSelector(c.spec.TagSelector)
+		}
+		if scope := entryScope(&c.spec); scope != "" {
+			identifier += " [" + scope + "]"
+		}
+		row := genRow(namespace, identifier, string(c.logSeverity), c.verbosity)
+		if selected >= 0 {
+			marker := ""
+			if i == selected {
+				marker = ">"
+			}
+			row = append([]string{marker}, row...)
+		}
+		table.Append(row)
 	}
 
 	table.Render()
+}
+
+func showLogSetting(ctx context.Context) error {
+	componentConfiguration, err := collectLogLevelConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+
+	writeLogSettingTable(os.Stdout, sortByComponent(componentConfiguration), -1)
 	return nil
 }
 
@@ -54,11 +84,20 @@ func showLogSetting(ctx context.Context) error {
 func Show(ctx context.Context, args []string) error {
 	doc := `Usage:
   helper log-level show
+  helper log-level show --resolve=<component> [--tags=<tags>] [--node=<node>] [--pod=<pod>]
 Options:
-  -h --help             Show this screen.
+  -h --help                  Show this screen.
+     --resolve=<component>  Component, as <namespace>/<identifier>, for which to explain
+                            which entry applies.
+     --tags=<tags>          Tags the component is registered with, as <key>=<value>[,<key>=<value>].
+     --node=<node>          Node the component runs on.
+     --pod=<pod>            Pod, as <namespace>/<name>, the component runs in. It decides
+                            whether entries limited to a percentage of replicas apply.
      
 Description:
   The log-level show command shows information about current log verbosity.
+  With --resolve, entries matching the component are listed from the one which
+  applies to the least specific one.
 `
 	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
 	if err != nil {
@@ -71,5 +110,37 @@ Description:
 		return nil
 	}
 
-	return showLogSetting(ctx)
+	passedComponent := parsedArgs["--resolve"]
+	if passedComponent == nil {
+		return showLogSetting(ctx)
+	}
+
+	component, err := parseComponent(passedComponent.(string))
+	if err != nil {
+		return err
+	}
+
+	var tags map[string]string
+	if passedTags := parsedArgs["--tags"]; passedTags != nil {
+		tags, err = parseTags(passedTags.(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	node := ""
+	if passedNode := parsedArgs["--node"]; passedNode != nil {
+		node = passedNode.(string)
+	}
+
+	replica := ""
+	if passedPod := parsedArgs["--pod"]; passedPod != nil {
+		_, replica, err = parsePod(passedPod.(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	return resolveLogSetting(ctx, os.Stdout,
+		&lib.Target{Component: component, Tags: tags, Node: node, Replica: replica})
 }
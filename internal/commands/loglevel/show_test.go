@@ -26,6 +26,8 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -85,4 +87,90 @@ var _ = Describe("Show", func() {
 		Expect(found).To(BeTrue())
 		os.Stdout = old
 	})
+
+	It("show displays resolved verbosity", func() {
+		component1 := v1alpha1.Component{Namespace: "eng", Identifier: "ui"}
+		component2 := v1alpha1.Component{Namespace: "eng", Identifier: "api"}
+		component3 := v1alpha1.Component{Namespace: "eng", Identifier: "db"}
+
+		dc := getLogSetting()
+		dc.Spec.Mapping = &v1alpha1.VerbosityMapping{Debug: pointer.Int32(7)}
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component1, LogLevel: v1alpha1.LogLevelDebug},
+			{
+				Component: component2, LogLevel: v1alpha1.LogLevelDebug,
+				Mapping: &v1alpha1.VerbosityMapping{Debug: pointer.Int32(8)},
+			},
+			{Component: component3, LogLevel: v1alpha1.LogLevelVerbose},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err = loglevel.ShowLogSetting(context.TODO())
+		w.Close()
+		os.Stdout = old
+		Expect(err).To(BeNil())
+
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, r)
+		Expect(err).To(BeNil())
+
+		// Resolved verbosity by component identifier
+		resolved := make(map[string]string)
+		for _, line := range strings.Split(buf.String(), "\n") {
+			fields := strings.Split(line, "|")
+			if len(fields) < 5 {
+				continue
+			}
+			resolved[strings.TrimSpace(fields[2])] = strings.TrimSpace(fields[4])
+		}
+
+		Expect(resolved[component1.Identifier]).To(Equal("7"))
+		Expect(resolved[component2.Identifier]).To(Equal("8"))
+		Expect(resolved[component3.Identifier]).To(Equal("10 (default)"))
+	})
+
+	It("show displays entries targeting tags", func() {
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{
+				TagSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+				LogLevel:    v1alpha1.LogLevelDebug,
+			},
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		old := os.Stdout
+		r, w, _ := os.Pipe()
+		os.Stdout = w
+
+		err = loglevel.ShowLogSetting(context.TODO())
+		w.Close()
+		os.Stdout = old
+		Expect(err).To(BeNil())
+
+		var buf bytes.Buffer
+		_, err = io.Copy(&buf, r)
+		Expect(err).To(BeNil())
+		Expect(buf.String()).To(ContainSubstring("tags: team=payments"))
+
+		// Setting a component never changes entries targeting tags
+		Expect(loglevel.UpdateLogSetting(context.TODO(), v1alpha1.LogLevelVerbose,
+			v1alpha1.Component{}, loglevel.DryRunNone)).To(Succeed())
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Spec.Configuration)).To(Equal(2))
+		Expect(currentDC.Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelDebug))
+	})
 })
@@ -0,0 +1,292 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	docopt "github.com/docopt/docopt-go"
+	"golang.org/x/term"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyRaise = "+"
+	keyLower = "-"
+	keyUnset = "u"
+	keyQuit  = "q"
+)
+
+// levels lists log severities from the least to the most verbose.
+var levels = []v1alpha1.LogLevel{
+	v1alpha1.LogLevelError,
+	v1alpha1.LogLevelWarning,
+	v1alpha1.LogLevelInfo,
+	v1alpha1.LogLevelDebug,
+	v1alpha1.LogLevelVerbose,
+}
+
+const tuiHelp = "up/down (k/j): select  +: raise  -: lower  u: unset  q: quit"
+
+// logLevelTUI is the state of the interactive terminal UI.
+type logLevelTUI struct {
+	entries  []*componentConfiguration
+	selected int
+	status   string
+}
+
+// reload fetches current configuration keeping, when possible, the same
+// component selected.
+func (t *logLevelTUI) reload(ctx context.Context) error {
+	var selected *v1alpha1.Component
+	if t.selected < len(t.entries) {
+		selected = &t.entries[t.selected].component
+	}
+
+	entries, err := collectLogLevelConfiguration(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries = sortByComponent(entries)
+	t.entries = entries
+	t.selected = 0
+	for i := range entries {
+		if selected != nil && entries[i].component == *selected {
+			t.selected = i
+		}
+	}
+
+	return nil
+}
+
+// shiftLevel returns the level delta positions away from current one.
+// Unset level is considered Info.
+func shiftLevel(current v1alpha1.LogLevel, delta int) v1alpha1.LogLevel {
+	index, infoIndex := -1, 0
+	for i := range levels {
+		switch levels[i] {
+		case current:
+			index = i
+		case v1alpha1.LogLevelInfo:
+			infoIndex = i
+		}
+	}
+	if index < 0 {
+		index = infoIndex
+	}
+
+	index += delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(levels) {
+		index = len(levels) - 1
+	}
+
+	return levels[index]
+}
+
+// handleKey processes a key. It returns true when user asked to quit.
+func (t *logLevelTUI) handleKey(ctx context.Context, key string) (bool, error) {
+	switch key {
+	case keyQuit:
+		return true, nil
+	case keyUp:
+		if t.selected > 0 {
+			t.selected--
+		}
+		return false, nil
+	case keyDown:
+		if t.selected < len(t.entries)-1 {
+			t.selected++
+		}
+		return false, nil
+	}
+
+	if t.selected >= len(t.entries) {
+		return false, nil
+	}
+
+	current := t.entries[t.selected]
+	if !current.managed() && (key == keyRaise || key == keyLower || key == keyUnset) {
+		t.status = "entries targeting tags, nodes or replicas, or with a schedule, cannot be changed here"
+		return false, nil
+	}
+	var err error
+
+	switch key {
+	case keyRaise, keyLower:
+		delta := 1
+		if key == keyLower {
+			delta = -1
+		}
+		level := shiftLevel(current.logSeverity, delta)
+		err = updateLogSetting(ctx, level, current.component, dryRunNone)
+		t.status = fmt.Sprintf("%s/%s set to %s",
+			current.component.Namespace, current.component.Identifier, level)
+	case keyUnset:
+		err = unsetLogSetting(ctx, current.component, dryRunNone)
+		t.status = fmt.Sprintf("%s/%s unset",
+			current.component.Namespace, current.component.Identifier)
+	default:
+		return false, nil
+	}
+
+	if err != nil {
+		t.status = err.Error()
+		return false, nil
+	}
+
+	return false, t.reload(ctx)
+}
+
+// render draws the UI. Terminal is in raw mode so each new line needs a
+// carriage return as well.
+func (t *logLevelTUI) render(w io.Writer) error {
+	var buf strings.Builder
+	writeLogSettingTable(&buf, t.entries, t.selected)
+	buf.WriteString(tuiHelp + "\n")
+	if t.status != "" {
+		buf.WriteString(t.status + "\n")
+	}
+
+	// Move cursor to top-left and clear screen
+	_, err := io.WriteString(w, "\x1b[H\x1b[2J"+strings.ReplaceAll(buf.String(), "\n", "\r\n"))
+	return err
+}
+
+// readKeys sends pressed keys on the returned channel.
+func readKeys(r io.Reader) <-chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 3)
+		for {
+			n, err := r.Read(buf)
+			if err != nil {
+				return
+			}
+			switch input := string(buf[:n]); input {
+			case "\x1b[A", "k":
+				keys <- keyUp
+			case "\x1b[B", "j":
+				keys <- keyDown
+			case "\x03":
+				keys <- keyQuit
+			default:
+				keys <- input
+			}
+		}
+	}()
+	return keys
+}
+
+func runTUI(ctx context.Context) error {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("log-level tui requires a terminal")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	changes := make(chan struct{}, 1)
+	err := utils.GetAccessInstance().WatchLogSetting(ctx, func() {
+		select {
+		case changes <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = term.Restore(fd, oldState)
+	}()
+
+	t := &logLevelTUI{}
+	if err := t.reload(ctx); err != nil {
+		return err
+	}
+
+	keys := readKeys(os.Stdin)
+	for {
+		if err := t.render(os.Stdout); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changes:
+			if err := t.reload(ctx); err != nil {
+				t.status = err.Error()
+			}
+		case key, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			quit, err := t.handleKey(ctx, key)
+			if err != nil {
+				return err
+			}
+			if quit {
+				return nil
+			}
+		}
+	}
+}
+
+// Tui allows browsing and changing log verbosity interactively
+func Tui(ctx context.Context, args []string) error {
+	doc := `Usage:
+  helper log-level tui
+Options:
+  -h --help             Show this screen.
+
+Description:
+  The log-level tui command shows current log verbosity settings and keeps
+  them updated. Log severity of the selected component can be raised, lowered
+  or unset with keystrokes.
+`
+	parsedArgs, err := docopt.ParseArgs(doc, nil, "1.0")
+	if err != nil {
+		return fmt.Errorf(
+			"invalid option: 'helper %s'. Use flag '--help' to read about a specific subcommand",
+			strings.Join(args, " "),
+		)
+	}
+	if len(parsedArgs) == 0 {
+		return nil
+	}
+
+	return runTUI(ctx)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loglevel_test
+
+import (
+	"bytes"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/internal/commands/loglevel"
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("Tui", func() {
+	var component1, component2 v1alpha1.Component
+
+	BeforeEach(func() {
+		component1 = v1alpha1.Component{Namespace: "ops", Identifier: "alerts"}
+		component2 = v1alpha1.Component{Namespace: "ops", Identifier: "metrics"}
+
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component1, LogLevel: v1alpha1.LogLevelInfo},
+			{Component: component2, LogLevel: v1alpha1.LogLevelVerbose},
+		}
+
+		initObjects := []client.Object{dc}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+	})
+
+	It("raises, lowers and unsets log severity of selected component", func() {
+		t := &loglevel.LogLevelTUI{}
+		Expect(t.Reload(context.TODO())).To(Succeed())
+
+		quit, err := t.HandleKey(context.TODO(), "+")
+		Expect(err).To(BeNil())
+		Expect(quit).To(BeFalse())
+		Expect(getLogLevel(component1)).To(Equal(v1alpha1.LogLevelDebug))
+
+		// Selection moves to second component which is already verbose
+		_, err = t.HandleKey(context.TODO(), "down")
+		Expect(err).To(BeNil())
+		_, err = t.HandleKey(context.TODO(), "+")
+		Expect(err).To(BeNil())
+		Expect(getLogLevel(component2)).To(Equal(v1alpha1.LogLevelVerbose))
+
+		_, err = t.HandleKey(context.TODO(), "-")
+		Expect(err).To(BeNil())
+		Expect(getLogLevel(component2)).To(Equal(v1alpha1.LogLevelDebug))
+
+		_, err = t.HandleKey(context.TODO(), "u")
+		Expect(err).To(BeNil())
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(len(currentDC.Spec.Configuration)).To(Equal(1))
+		Expect(currentDC.Spec.Configuration[0].Component).To(Equal(component1))
+
+		var buf bytes.Buffer
+		Expect(t.Render(&buf)).To(Succeed())
+		Expect(buf.String()).To(ContainSubstring(component1.Identifier))
+		Expect(buf.String()).To(ContainSubstring(string(v1alpha1.LogLevelDebug)))
+		Expect(buf.String()).To(ContainSubstring("ops/metrics unset"))
+
+		quit, err = t.HandleKey(context.TODO(), "q")
+		Expect(err).To(BeNil())
+		Expect(quit).To(BeTrue())
+	})
+
+	It("lowers log severity down to error", func() {
+		t := &loglevel.LogLevelTUI{}
+		Expect(t.Reload(context.TODO())).To(Succeed())
+
+		_, err := t.HandleKey(context.TODO(), "-")
+		Expect(err).To(BeNil())
+		Expect(getLogLevel(component1)).To(Equal(v1alpha1.LogLevelWarning))
+
+		_, err = t.HandleKey(context.TODO(), "-")
+		Expect(err).To(BeNil())
+		Expect(getLogLevel(component1)).To(Equal(v1alpha1.LogLevelError))
+
+		_, err = t.HandleKey(context.TODO(), "-")
+		Expect(err).To(BeNil())
+		Expect(getLogLevel(component1)).To(Equal(v1alpha1.LogLevelError))
+	})
+
+	It("reports failures on status line", func() {
+		t := &loglevel.LogLevelTUI{}
+		Expect(t.Reload(context.TODO())).To(Succeed())
+
+		// LogSetting cannot be fetched by a client not knowing its type
+		c := fake.NewClientBuilder().WithScheme(runtime.NewScheme()).Build()
+		utils.InitalizeManagementClusterAcces(runtime.NewScheme(), nil, nil, c)
+
+		for _, key := range []string{"+", "u"} {
+			_, err := t.HandleKey(context.TODO(), key)
+			Expect(err).To(BeNil())
+
+			var buf bytes.Buffer
+			Expect(t.Render(&buf)).To(Succeed())
+			Expect(buf.String()).ToNot(ContainSubstring("set to"))
+			Expect(buf.String()).ToNot(ContainSubstring("unset\r\n"))
+			Expect(buf.String()).To(ContainSubstring("no kind is registered"))
+		}
+	})
+})
+
+func getLogLevel(component v1alpha1.Component) v1alpha1.LogLevel {
+	currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+	Expect(err).To(BeNil())
+	for i := range currentDC.Spec.Configuration {
+		if currentDC.Spec.Configuration[i].Component == component {
+			return currentDC.Spec.Configuration[i].LogLevel
+		}
+	}
+	return v1alpha1.LogLevelNotSet
+}
@@ -26,33 +26,27 @@ import (
 	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
 )
 
-func unsetLogSetting(ctx context.Context, component v1alpha1.Component) error {
+func unsetLogSetting(ctx context.Context, component v1alpha1.Component, mode dryRun) error {
 	cc, err := collectLogLevelConfiguration(ctx)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	found := false
 	spec := make([]v1alpha1.ComponentConfiguration, 0)
 
 	for _, c := range cc {
-		if c.component.Namespace == component.Namespace &&
-			c.component.Identifier == component.Identifier {
+		if c.targets(component) {
 
 			found = true
 			continue
 		} else {
-			spec = append(spec,
-				v1alpha1.ComponentConfiguration{
-					Component: c.component,
-					LogLevel:  c.logSeverity,
-				},
-			)
+			spec = append(spec, c.spec)
 		}
 	}
 
 	if found {
-		return updateLogLevelConfiguration(ctx, spec)
+		return updateLogLevelConfiguration(ctx, spec, mode)
 	}
 	return nil
 }
@@ -60,10 +54,15 @@ func unsetLogSetting(ctx context.Context, component v1alpha1.Component) error {
 // Unset resets log verbosity for a given component
 func Unset(ctx context.Context, args []string) error {
 	doc := `Usage:
-  helper log-level unset --component=<name>
+  helper log-level unset --namespace=<namespace> --identifier=<identifier> [--dry-run=<mode>]
+  helper log-level unset --pod=<pod> [--dry-run=<mode>]
 Options:
-  -h --help             Show this screen.
-     --component=<name> Name of the component for which log severity is being set.
+  -h --help                    Show this screen.
+     --namespace=<namespace>   Namespace of the component for which log severity is being unset.
+     --identifier=<identifier> Identifier of the component for which log severity is being unset.
+     --pod=<pod>               Pod, as <namespace>/<name>, whose log severity override is being removed.
+     --dry-run=<mode>          Preview the change. With client, resulting LogSetting (or Pod) is printed.
+                               With server, change is validated by the API server but not persisted.
 	 
 Description:
   The log-level set command set log severity for the specified component.
@@ -79,6 +78,19 @@ Description:
 		return nil
 	}
 
+	mode, err := parseDryRun(parsedArgs["--dry-run"])
+	if err != nil {
+		return err
+	}
+
+	if passedPod := parsedArgs["--pod"]; passedPod != nil {
+		podNamespace, podName, err := parsePod(passedPod.(string))
+		if err != nil {
+			return err
+		}
+		return setPodLogLevel(ctx, podNamespace, podName, v1alpha1.LogLevelNotSet, mode)
+	}
+
 	namespace := ""
 	if passedNamespace := parsedArgs["--namespace"]; passedNamespace != nil {
 		namespace = passedNamespace.(string)
@@ -89,5 +101,5 @@ Description:
 		identifier = passedIdentifier.(string)
 	}
 
-	return unsetLogSetting(ctx, v1alpha1.Component{Namespace: namespace, Identifier: identifier})
+	return unsetLogSetting(ctx, v1alpha1.Component{Namespace: namespace, Identifier: identifier}, mode)
 }
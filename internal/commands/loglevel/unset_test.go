@@ -48,7 +48,7 @@ var _ = Describe("Unset", func() {
 
 		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
 
-		Expect(loglevel.UnsetLogSetting(context.TODO(), component1)).To(Succeed())
+		Expect(loglevel.UnsetLogSetting(context.TODO(), component1, loglevel.DryRunNone)).To(Succeed())
 
 		k8sAccess := utils.GetAccessInstance()
 
@@ -61,4 +61,31 @@ var _ = Describe("Unset", func() {
 		Expect(currentDC.Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelInfo))
 
 	})
+
+	It("unset keeps scheduled entries", func() {
+		component := v1alpha1.Component{Namespace: "hr", Identifier: "ptos"}
+		scheduled := v1alpha1.ComponentConfiguration{
+			Component: component,
+			LogLevel:  v1alpha1.LogLevelVerbose,
+			Schedule:  &v1alpha1.Schedule{Start: "22:00", End: "04:00"},
+		}
+
+		dc := getLogSetting()
+		dc.Spec.Configuration = []v1alpha1.ComponentConfiguration{
+			{Component: component, LogLevel: v1alpha1.LogLevelDebug},
+			scheduled,
+		}
+
+		scheme, err := utils.GetScheme()
+		Expect(err).To(BeNil())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dc).Build()
+
+		utils.InitalizeManagementClusterAcces(scheme, nil, nil, c)
+
+		Expect(loglevel.UnsetLogSetting(context.TODO(), component, loglevel.DryRunNone)).To(Succeed())
+
+		currentDC, err := utils.GetAccessInstance().GetLogSetting(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(currentDC.Spec.Configuration).To(Equal([]v1alpha1.ComponentConfiguration{scheduled}))
+	})
 })
@@ -18,18 +18,137 @@ package loglevel
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"sort"
+	"strconv"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
 	"github.com/gianlucam76/pod-log-level/internal/utils"
+	"github.com/gianlucam76/pod-log-level/lib"
 )
 
+// dryRun indicates whether a change must only be previewed.
+type dryRun string
+
+const (
+	// dryRunNone persists changes
+	dryRunNone = dryRun("none")
+
+	// dryRunClient prints resulting LogSetting without sending it to the API server
+	dryRunClient = dryRun("client")
+
+	// dryRunServer sends the change to the API server without persisting it
+	dryRunServer = dryRun("server")
+)
+
+// parseDryRun converts the --dry-run option value. Empty value means changes are persisted.
+func parseDryRun(value interface{}) (dryRun, error) {
+	if value == nil {
+		return dryRunNone, nil
+	}
+
+	switch mode := dryRun(value.(string)); mode {
+	case dryRunNone, dryRunClient, dryRunServer:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid dry-run value %q. Allowed values are none, client and server", mode)
+	}
+}
+
+// builtinLevels maps the names accepted by --level to built-in log levels
+var builtinLevels = map[string]v1alpha1.LogLevel{
+	"error":   v1alpha1.LogLevelError,
+	"warning": v1alpha1.LogLevelWarning,
+	"info":    v1alpha1.LogLevelInfo,
+	"debug":   v1alpha1.LogLevelDebug,
+	"verbose": v1alpha1.LogLevelVerbose,
+}
+
+// parseLevel returns the log level name refers to. name is either a built-in
+// level (error, warning, info, debug or verbose) or a level declared in the
+// LogSetting instance customLevels.
+func parseLevel(ctx context.Context, name string) (v1alpha1.LogLevel, error) {
+	if level, ok := builtinLevels[name]; ok {
+		return level, nil
+	}
+
+	dc, err := utils.GetAccessInstance().GetLogSetting(ctx)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return "", err
+	}
+	if err == nil {
+		for i := range dc.Spec.CustomLevels {
+			if dc.Spec.CustomLevels[i].Name == name {
+				return v1alpha1.LogLevel(name), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("unknown log level %q. Use error, warning, info, debug, verbose or a level declared in customLevels",
+		name)
+}
+
 type componentConfiguration struct {
 	component   v1alpha1.Component
 	logSeverity v1alpha1.LogLevel
+
+	// verbosity is the klog verbosity logSeverity resolves to
+	verbosity string
+
+	// spec is the LogSetting entry as found in the cluster. Changes
+	// made via CLI start from it, so fields CLI does not manage are preserved.
+	spec v1alpha1.ComponentConfiguration
+}
+
+// managed returns true if c can be changed via CLI: entries targeting
+// components by tag selector, limited to nodes or to a percentage of
+// replicas, or limited to a schedule, cannot.
+func (c *componentConfiguration) managed() bool {
+	return c.spec.TagSelector == nil && entryScope(&c.spec) == "" && c.spec.Schedule == nil
+}
+
+// targets returns true if c is the entry for component
+func (c *componentConfiguration) targets(component v1alpha1.Component) bool {
+	return c.managed() &&
+		c.component.Namespace == component.Namespace &&
+		c.component.Identifier == component.Identifier
+}
+
+// entryName returns how LogSetting entry c is displayed: <namespace>/<identifier>,
+// or tags(<selector>) for entries targeting tags, followed by the nodes and
+// the percentage of replicas entry is limited to, if any.
+func entryName(c *v1alpha1.ComponentConfiguration) string {
+	name := fmt.Sprintf("%s/%s", c.Component.Namespace, c.Component.Identifier)
+	if c.TagSelector != nil {
+		name = fmt.Sprintf("tags(%s)", metav1.FormatLabelSelector(c.TagSelector))
+	}
+	if scope := entryScope(c); scope != "" {
+		name += " [" + scope + "]"
+	}
+	return name
+}
+
+// entryScope describes the nodes and the percentage of replicas entry c is
+// limited to. Empty if it is not.
+func entryScope(c *v1alpha1.ComponentConfiguration) string {
+	var scope []string
+	if len(c.Nodes) != 0 {
+		scope = append(scope, "nodes: "+strings.Join(c.Nodes, ","))
+	}
+	if c.NodeSelector != nil {
+		scope = append(scope, "node labels: "+metav1.FormatLabelSelector(c.NodeSelector))
+	}
+	if c.ReplicaPercentage != nil {
+		scope = append(scope, fmt.Sprintf("replicas: %d%%", *c.ReplicaPercentage))
+	}
+	return strings.Join(scope, "; ")
 }
 
 // byComponent sorts componentConfiguration by name.
@@ -44,6 +163,18 @@ func (c byComponent) Less(i, j int) bool {
 	return c[i].component.Namespace < c[j].component.Namespace
 }
 
+// sortByComponent returns a copy of cc sorted by component name, for display.
+// Entries for the same component keep their order.
+func sortByComponent(cc []*componentConfiguration) []*componentConfiguration {
+	sorted := make([]*componentConfiguration, len(cc))
+	copy(sorted, cc)
+	sort.Stable(byComponent(sorted))
+	return sorted
+}
+
+// collectLogLevelConfiguration returns LogSetting entries in the order they
+// appear in LogSetting. Among entries equally specific the last one applies,
+// so changes made via CLI must preserve that order.
 func collectLogLevelConfiguration(ctx context.Context) ([]*componentConfiguration, error) {
 	instance := utils.GetAccessInstance()
 
@@ -61,18 +192,41 @@ func collectLogLevelConfiguration(ctx context.Context) ([]*componentConfiguratio
 		configurationSettings[i] = &componentConfiguration{
 			component:   c.Component,
 			logSeverity: c.LogLevel,
+			verbosity:   resolveVerbosity(&dc.Spec, &dc.Spec.Configuration[i]),
+			spec:        c,
 		}
 	}
 
-	// Sort this by component name first. Component/node is higher priority than Component
-	sort.Sort(byComponent(configurationSettings))
-
 	return configurationSettings, nil
 }
 
+// resolveVerbosity returns the klog verbosity c log level resolves to.
+// When LogSetting does not map it, library default is returned marked as
+// such, since components can change it.
+func resolveVerbosity(spec *v1alpha1.LogSettingSpec, c *v1alpha1.ComponentConfiguration) string {
+	level := lib.EntryLogLevel(c)
+	if v, ok := lib.ResolveVerbosity(spec, c, level); ok {
+		return strconv.Itoa(int(v))
+	}
+
+	var v int
+	switch level {
+	case v1alpha1.LogLevelVerbose:
+		v = lib.LogVerbose
+	case v1alpha1.LogLevelDebug:
+		v = lib.LogDebug
+	case v1alpha1.LogLevelInfo, v1alpha1.LogLevelWarning, v1alpha1.LogLevelError:
+		v = lib.LogInfo
+	default:
+		return ""
+	}
+	return fmt.Sprintf("%d (default)", v)
+}
+
 func updateLogLevelConfiguration(
 	ctx context.Context,
 	spec []v1alpha1.ComponentConfiguration,
+	mode dryRun,
 ) error {
 
 	instance := utils.GetAccessInstance()
@@ -90,9 +244,43 @@ func updateLogLevelConfiguration(
 		}
 	}
 
-	dc.Spec = v1alpha1.LogSettingSpec{
-		Configuration: spec,
+	now := metav1.Now()
+	// Configuration set outside CLI is recorded before being changed, so
+	// it can be restored
+	if dc.ResourceVersion != "" && !isRecorded(dc) {
+		recordRevision(dc, "", now)
+	}
+
+	// Only configuration is changed: mapping and custom levels are preserved
+	dc.Spec.Configuration = spec
+
+	recordRevision(dc, getAuthor(), now)
+
+	switch mode {
+	case dryRunClient:
+		return printLogSetting(dc)
+	case dryRunServer:
+		return instance.UpdateLogSetting(ctx, dc, client.DryRunAll)
+	default:
+		return instance.UpdateLogSetting(ctx, dc)
+	}
+}
+
+// printLogSetting writes LogSetting in YAML format to the stdout
+func printLogSetting(dc *v1alpha1.LogSetting) error {
+	dc.APIVersion = v1alpha1.GroupVersion.String()
+	dc.Kind = "LogSetting"
+
+	return printObject(dc)
+}
+
+// printObject writes obj in YAML format to the stdout
+func printObject(obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
 	}
 
-	return instance.UpdateLogSetting(ctx, dc)
+	_, err = fmt.Fprint(os.Stdout, string(data))
+	return err
 }
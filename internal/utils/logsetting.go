@@ -19,7 +19,11 @@ package utils
 import (
 	"context"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
@@ -48,12 +52,17 @@ func (a *k8sAccess) GetLogSetting(
 }
 
 // UpdateLogSetting creates, if not existing already, default LogSetting. Otherwise
-// updates it.
+// updates it. When opts contain client.DryRunAll, request is sent to the API server
+// but nothing is persisted.
 func (a *k8sAccess) UpdateLogSetting(
 	ctx context.Context,
 	dc *v1alpha1.LogSetting,
+	opts ...client.UpdateOption,
 ) error {
 
+	updateOptions := &client.UpdateOptions{}
+	updateOptions.ApplyOptions(opts)
+
 	reqName := client.ObjectKey{
 		Name: defaultInstanceName,
 	}
@@ -63,19 +72,57 @@ func (a *k8sAccess) UpdateLogSetting(
 	err := a.client.Get(ctx, reqName, tmp)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
-			err = a.client.Create(ctx, dc)
+			err = a.client.Create(ctx, dc, &client.CreateOptions{DryRun: updateOptions.DryRun})
 			if err != nil {
 				return err
 			}
+			if len(updateOptions.DryRun) > 0 {
+				// Instance was not persisted, so there is nothing to update
+				return nil
+			}
 		} else {
 			return err
 		}
 	}
 
-	err = a.client.Update(ctx, dc)
+	err = a.client.Update(ctx, dc, opts...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// WatchLogSetting starts an informer on LogSetting instances. Handler is invoked
+// every time an instance is created, updated or deleted. Informer stops when
+// ctx is cancelled.
+func (a *k8sAccess) WatchLogSetting(
+	ctx context.Context,
+	handler func(),
+) error {
+
+	dc, err := dynamic.NewForConfig(a.restConfig)
+	if err != nil {
+		return err
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
+		dc,
+		0,
+		corev1.NamespaceAll,
+		nil,
+	)
+	informer := factory.ForResource(v1alpha1.GroupVersion.WithResource("logsettings")).Informer()
+
+	_, err = informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { handler() },
+		UpdateFunc: func(oldObj, newObj interface{}) { handler() },
+		DeleteFunc: func(obj interface{}) { handler() },
+	})
 	if err != nil {
 		return err
 	}
 
+	go informer.Run(ctx.Done())
 	return nil
 }
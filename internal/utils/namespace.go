@@ -0,0 +1,41 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ListNamespaces returns the names of all namespaces in the management cluster
+func (a *k8sAccess) ListNamespaces(
+	ctx context.Context,
+) ([]string, error) {
+
+	namespaces := &corev1.NamespaceList{}
+	if err := a.client.List(ctx, namespaces); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(namespaces.Items))
+	for i := range namespaces.Items {
+		names[i] = namespaces.Items[i].Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/gianlucam76/pod-log-level/internal/utils"
+)
+
+var _ = Describe("Namespaces", func() {
+	It("ListNamespaces returns all namespaces", func() {
+		initObjects := []client.Object{
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "projectsveltos"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		}
+
+		scheme := runtime.NewScheme()
+		Expect(utils.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(initObjects...).Build()
+
+		k8sAccess := utils.GetK8sAccess(scheme, c)
+		namespaces, err := k8sAccess.ListNamespaces(context.TODO())
+		Expect(err).To(BeNil())
+		Expect(namespaces).To(ConsistOf("projectsveltos", "kube-system"))
+	})
+})
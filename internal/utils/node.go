@@ -0,0 +1,38 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNode gets the Node name
+func (a *k8sAccess) GetNode(
+	ctx context.Context,
+	name string,
+) (*corev1.Node, error) {
+
+	node := &corev1.Node{}
+	if err := a.client.Get(ctx, client.ObjectKey{Name: name}, node); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2023
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetPod gets the Pod namespace/name
+func (a *k8sAccess) GetPod(
+	ctx context.Context,
+	namespace, name string,
+) (*corev1.Pod, error) {
+
+	pod := &corev1.Pod{}
+	if err := a.client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod); err != nil {
+		return nil, err
+	}
+
+	return pod, nil
+}
+
+// PatchPod patches pod. When opts contain client.DryRunAll, request is sent to
+// the API server but nothing is persisted.
+func (a *k8sAccess) PatchPod(
+	ctx context.Context,
+	pod *corev1.Pod,
+	patch client.Patch,
+	opts ...client.PatchOption,
+) error {
+
+	return a.client.Patch(ctx, pod, patch, opts...)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Concurrency", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("applies new severity values immediately", func() {
+		instance.SetDebugValue(lib.LogDebug)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelDebug},
+				},
+			},
+		})
+
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		instance.SetDebugValue(7)
+		Expect(f.Value.String()).To(Equal("7"))
+
+		instance.SetDebugValue(lib.LogDebug)
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+	})
+
+	// This test is meaningful when run with -race
+	It("handles concurrent updates", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelDebug,
+						Objects:   []v1alpha1.ObjectTarget{{Kind: "Pod", Name: "nginx"}},
+						Sampling:  &v1alpha1.Sampling{First: 10, Thereafter: 10},
+					},
+				},
+			},
+		}
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"}}
+
+		recorder := instance.NewFlightRecorderLogger(klogr.New(), 10)
+		sampling := instance.NewSamplingLogger(klogr.New())
+		escalation := instance.NewErrorEscalationLogger(klogr.New())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(6)
+			go func() {
+				defer wg.Done()
+				instance.SetDebugValue(lib.LogDebug)
+				instance.SetVerboseValue(lib.LogVerbose)
+			}()
+			go func() {
+				defer wg.Done()
+				lib.UpdateLogLevel(conf.DeepCopy())
+			}()
+			go func() {
+				defer wg.Done()
+				instance.LoggerFor(pod).V(lib.LogDebug).Info("concurrent")
+			}()
+			go func() {
+				defer wg.Done()
+				recorder.V(lib.LogDebug).Info("concurrent")
+			}()
+			go func() {
+				defer wg.Done()
+				sampling.V(lib.LogDebug).Info("concurrent")
+			}()
+			go func() {
+				defer wg.Done()
+				escalation.Error(errors.New("failure"), "concurrent")
+			}()
+		}
+		wg.Wait()
+
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+	})
+
+	It("logs without waiting for configuration to be applied", func() {
+		recorder := instance.NewFlightRecorderLogger(klogr.New(), 10)
+		sampling := instance.NewSamplingLogger(klogr.New())
+
+		unlock := instance.Lock()
+		defer unlock()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			recorder.V(lib.LogDebug).Info("while locked")
+			sampling.V(lib.LogDebug).Info("while locked")
+		}()
+		Eventually(done).Should(BeClosed())
+	})
+})
@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// escalationSink is a LogSink which reports every error logged to LogSetter
+type escalationSink struct {
+	sink   logr.LogSink
+	setter *LogSetter
+}
+
+// NewErrorEscalationLogger returns a logger which counts errors logged through it.
+// If the LogSetting entry for this component has ErrorEscalation set and more than
+// Threshold errors are logged within Window, log severity is raised to debug for
+// CoolDown. Log severity then goes back to the one set by LogSetting.
+func (l *LogSetter) NewErrorEscalationLogger(logger logr.Logger) logr.Logger {
+	return logr.New(&escalationSink{sink: wrapSink(logger.GetSink()), setter: l})
+}
+
+// Init is a no-op: wrapped sink is already initialized.
+func (s *escalationSink) Init(info logr.RuntimeInfo) {
+}
+
+func (s *escalationSink) Enabled(level int) bool {
+	return s.sink.Enabled(level)
+}
+
+func (s *escalationSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.sink.Info(level, msg, keysAndValues...)
+}
+
+func (s *escalationSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+	s.setter.recordError(time.Now())
+}
+
+func (s *escalationSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &escalationSink{sink: s.sink.WithValues(keysAndValues...), setter: s.setter}
+}
+
+func (s *escalationSink) WithName(name string) logr.LogSink {
+	return &escalationSink{sink: s.sink.WithName(name), setter: s.setter}
+}
+
+func (s *escalationSink) WithCallDepth(depth int) logr.LogSink {
+	return &escalationSink{sink: withCallDepth(s.sink, depth), setter: s.setter}
+}
+
+// recordError counts an error logged at now. If errors exceed the
+// configured threshold, log severity is raised to debug. It never takes mu:
+// the LogSetter logs while holding it, so escalation happens asynchronously.
+func (l *LogSetter) recordError(now time.Time) {
+	state := l.published()
+	if state.escalated || state.escalation == nil {
+		return
+	}
+	escalation := state.escalation
+
+	l.errorsMu.Lock()
+	defer l.errorsMu.Unlock()
+
+	// Forget errors which are outside the window
+	windowStart := now.Add(-escalation.Window.Duration)
+	errorTimes := l.errorTimes[:0]
+	for _, t := range l.errorTimes {
+		if t.After(windowStart) {
+			errorTimes = append(errorTimes, t)
+		}
+	}
+	l.errorTimes = append(errorTimes, now)
+
+	if len(l.errorTimes) <= int(escalation.Threshold) {
+		return
+	}
+
+	count := len(l.errorTimes)
+	l.errorTimes = nil
+	go l.escalate(escalation, count)
+}
+
+// escalate raises log severity to debug till escalation cool-down expires.
+func (l *LogSetter) escalate(escalation *v1alpha1.ErrorEscalation, count int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.escalated {
+		return
+	}
+
+	l.logger.Info("Too many errors. Raising log severity to debug",
+		"errors", count, "window", escalation.Window.Duration,
+		"coolDown", escalation.CoolDown.Duration)
+	l.escalated = true
+	// While escalated, log severity is never set below debug
+	l.updateLogLevel(l.logSetting)
+	time.AfterFunc(escalation.CoolDown.Duration, l.endEscalation)
+}
+
+func (l *LogSetter) endEscalation() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.logger.Info("Error escalation cool-down expired")
+	l.escalated = false
+	l.updateLogLevel(l.logSetting)
+}
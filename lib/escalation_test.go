@@ -0,0 +1,138 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("ErrorEscalation", func() {
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("raises log severity to debug on error bursts", func() {
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelInfo,
+						ErrorEscalation: &v1alpha1.ErrorEscalation{
+							Threshold: 2,
+							Window:    metav1.Duration{Duration: time.Minute},
+							CoolDown:  metav1.Duration{Duration: time.Second},
+						},
+					},
+				},
+			},
+		}
+
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		lib.UpdateLogLevel(conf)
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		logger := instance.NewErrorEscalationLogger(klogr.New())
+		for i := 0; i < 2; i++ {
+			logger.Error(errors.New("failure"), "test error")
+		}
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		logger.WithValues("attempt", 3).Error(errors.New("failure"), "test error")
+		Eventually(func() string {
+			return instance.GetVerbosity()
+		}, time.Second, 10*time.Millisecond).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		// LogSetting changes do not end escalation
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		Eventually(func() string {
+			return instance.GetVerbosity()
+		}, 5*time.Second, 100*time.Millisecond).Should(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+
+	It("does not block errors logged while configuration is being applied", func() {
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelInfo,
+						ErrorEscalation: &v1alpha1.ErrorEscalation{
+							Threshold: 1,
+							Window:    metav1.Duration{Duration: time.Minute},
+							CoolDown:  metav1.Duration{Duration: time.Second},
+						},
+					},
+				},
+			},
+		}
+
+		lib.UpdateLogLevel(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		logger := instance.NewErrorEscalationLogger(klogr.New())
+
+		unlock := instance.Lock()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 0; i < 3; i++ {
+				logger.Error(errors.New("failure"), "test error")
+			}
+		}()
+		Eventually(done, time.Second).Should(BeClosed())
+		unlock()
+
+		Eventually(func() string {
+			return instance.GetVerbosity()
+		}, time.Second, 10*time.Millisecond).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		Eventually(func() string {
+			return instance.GetVerbosity()
+		}, 5*time.Second, 100*time.Millisecond).Should(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+})
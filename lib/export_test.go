@@ -0,0 +1,173 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"flag"
+	"io"
+	"time"
+	"unsafe"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+var (
+	EvaluateSchedule = evaluateSchedule
+)
+
+// SetFlightRecorderOutput sets where flight recorders dump records
+func (l *LogSetter) SetFlightRecorderOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, recorder := range l.recorders {
+		recorder.mu.Lock()
+		recorder.out = w
+		recorder.mu.Unlock()
+	}
+}
+
+// FlightRecorderID identifies the flight recorder of logger, returned by
+// NewFlightRecorderLogger, without keeping it reachable
+func FlightRecorderID(logger logr.Logger) uintptr {
+	return uintptr(unsafe.Pointer(logger.GetSink().(*recorderSink).ref.recorder))
+}
+
+// HasFlightRecorder returns true if flight recorder id is in use
+func (l *LogSetter) HasFlightRecorder(id uintptr) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, recorder := range l.recorders {
+		if uintptr(unsafe.Pointer(recorder)) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// NewSamplingLoggerWithClock returns a sampling logger which uses now as clock
+func (l *LogSetter) NewSamplingLoggerWithClock(logger logr.Logger, now func() time.Time) logr.Logger {
+	sampler := newSampler()
+	sampler.now = now
+	return logr.New(&samplingSink{sink: wrapSink(logger.GetSink()), setter: l, sampler: sampler})
+}
+
+// SetDebugToken caches token as the content of key in Secret secretRef
+func (l *LogSetter) SetDebugToken(secretRef corev1.SecretReference, key, token string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debugToken = &debugToken{secretRef: secretRef, key: key, token: []byte(token),
+		expiry: time.Now().Add(time.Hour), done: make(chan struct{})}
+	close(l.debugToken.done)
+}
+
+// SetJSONOutput sets where logs are written in JSON format
+func SetJSONOutput(w io.Writer) {
+	jsonOutput = w
+}
+
+// GetVerbosity returns klog verbosity. It is read with mu held, so that it
+// does not race with changes made by timers.
+func (l *LogSetter) GetVerbosity() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return flag.Lookup("v").Value.String()
+}
+
+// SetPersistencePath sets where LogSetting is persisted
+func (l *LogSetter) SetPersistencePath(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.persistencePath = path
+}
+
+// RaiseSignalLevel raises log severity as SIGUSR1 does
+func (l *LogSetter) RaiseSignalLevel() {
+	l.raiseSignalLevel()
+}
+
+// ResetSignalLevel resets log severity as SIGUSR2 does
+func (l *LogSetter) ResetSignalLevel() {
+	l.resetSignalLevel()
+}
+
+// WatchPod sets log level from annotations of Pod namespace/name
+func (l *LogSetter) WatchPod(ctx context.Context, clientset kubernetes.Interface, namespace, name string) {
+	l.watchPod(ctx, clientset, namespace, name)
+}
+
+// PollAnnotationsFile reads the annotations file at path every interval
+func (l *LogSetter) PollAnnotationsFile(ctx context.Context, path string, interval time.Duration) {
+	l.pollAnnotationsFile(ctx, path, interval)
+}
+
+// ClearPodLevel removes any log level set by Pod annotation
+func (l *LogSetter) ClearPodLevel() {
+	l.setPodLevel(v1alpha1.LogLevelNotSet)
+}
+
+// SetTags sets tags the component is registered with
+func (l *LogSetter) SetTags(tags map[string]string) {
+	l.setTags(tags)
+}
+
+// SetNode sets the node the component runs on
+func (l *LogSetter) SetNode(name string) {
+	l.setNode(name)
+}
+
+// WatchNode sets node labels from Node name
+func (l *LogSetter) WatchNode(ctx context.Context, clientset kubernetes.Interface, name string) {
+	l.watchNode(ctx, clientset, name)
+}
+
+// ClearNode forgets the node the component runs on and its labels
+func (l *LogSetter) ClearNode() {
+	l.setNode("")
+	l.setNodeLabels(nil)
+}
+
+// SetReplica sets the name of the Pod the component runs in
+func (l *LogSetter) SetReplica(name string) {
+	l.setReplica(name)
+}
+
+// SetClientset sets the clientset used by LogSetter. It returns the previous one.
+func (l *LogSetter) SetClientset(clientset kubernetes.Interface) kubernetes.Interface {
+	l.clientsetOnce.Do(func() {})
+	previous := l.clientset
+	l.clientset, l.clientsetErr = clientset, nil
+	return previous
+}
+
+// ClearDebugToken forgets the cached request debug token
+func (l *LogSetter) ClearDebugToken() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.debugToken = nil
+}
+
+// Lock holds LogSetter lock, as done while configuration is being applied,
+// till returned function is called
+func (l *LogSetter) Lock() func() {
+	l.mu.Lock()
+	return l.mu.Unlock
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"k8s.io/klog/v2"
+	"k8s.io/klog/v2/textlogger"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// jsonOutput is where logs are written in JSON format
+var jsonOutput io.Writer = os.Stderr
+
+// encoder writes records in one log format
+type encoder struct {
+	// sink receives structured records, like those logged with klog.InfoS
+	sink logr.LogSink
+
+	// write receives records klog already formatted as text, like those
+	// logged with klog.Infof. It is nil if klog passes them to sink instead.
+	write func(record []byte)
+}
+
+// installFormatSink makes klog write to a formatSink, so that log format can
+// later be switched without calling klog.SetLogger again, which is not safe
+// while other goroutines log. original, if not nil, is the logger klog wrote
+// to before, which keeps receiving records in text format.
+// It is done only once, at registration.
+func (l *LogSetter) installFormatSink(original *logr.Logger) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.formatSinkSet {
+		return
+	}
+
+	l.textEncoder = l.newTextEncoder(original)
+	l.jsonEncoder = l.newJSONEncoder()
+	l.encoder.Store(l.textEncoder)
+	// Text output follows klog flags as set now
+	l.publish()
+
+	sink := &formatSink{setter: l}
+	if original != nil {
+		// klog passes all records to the sink, with their call site
+		klog.SetLogger(logr.New(sink))
+	} else {
+		// Records formatted by klog are written unchanged in text format
+		klog.SetLoggerWithOptions(logr.New(sink), klog.WriteKlogBuffer(sink.writeKlogBuffer))
+	}
+	l.formatSinkSet = true
+}
+
+// newTextEncoder returns the encoder for text format. Records go to original,
+// if not nil, or are written as klog itself would.
+func (l *LogSetter) newTextEncoder(original *logr.Logger) *encoder {
+	if original != nil {
+		return &encoder{sink: &quietSink{sink: wrapSink(original.GetSink()), setter: l}}
+	}
+
+	output := &textOutput{setter: l}
+	// klog already filtered records by verbosity
+	config := textlogger.NewConfig(textlogger.Output(output), textlogger.Verbosity(math.MaxInt32))
+	return &encoder{
+		sink:  textlogger.NewLogger(config).GetSink(),
+		write: func(record []byte) { _, _ = output.Write(record) },
+	}
+}
+
+// newJSONEncoder returns the encoder for JSON format. It drops records while
+// a quiet log level is set.
+func (l *LogSetter) newJSONEncoder() *encoder {
+	write := func(obj string) {
+		fmt.Fprintln(jsonOutput, obj)
+	}
+	// klog already filtered records by verbosity
+	sink := funcr.NewJSON(write, funcr.Options{LogCaller: funcr.All, LogTimestamp: true,
+		Verbosity: math.MaxInt32}).GetSink()
+	// Call site of records formatted by klog is taken from their header
+	formatted := funcr.NewJSON(write, funcr.Options{LogTimestamp: true,
+		Verbosity: math.MaxInt32}).GetSink()
+	return &encoder{
+		sink:  &quietSink{sink: wrapSink(sink), setter: l},
+		write: func(record []byte) { writeRecord(&quietSink{sink: formatted, setter: l}, record) },
+	}
+}
+
+// callerID is the call site of a record, as logged by funcr
+type callerID struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// writeRecord logs to sink a record klog formatted as text, whose header is
+// replaced by the record call site
+func writeRecord(sink logr.LogSink, record []byte) {
+	msg := bytes.TrimSuffix(record, []byte("\n"))
+	var keysAndValues []interface{}
+	// Header ends with the call site, like "file.go:10] "
+	if end := bytes.Index(msg, []byte("] ")); end >= 0 {
+		header := msg[:end]
+		msg = msg[end+2:]
+		site := header[bytes.LastIndexByte(header, ' ')+1:]
+		if i := bytes.LastIndexByte(site, ':'); i >= 0 {
+			line, _ := strconv.Atoi(string(site[i+1:]))
+			keysAndValues = append(keysAndValues, "caller", callerID{File: string(site[:i]), Line: line})
+		}
+	}
+
+	if recordSeverity(record) >= severityIndex("ERROR") {
+		sink.Error(nil, string(msg), keysAndValues...)
+		return
+	}
+	sink.Info(0, string(msg), keysAndValues...)
+}
+
+// formatSink is the LogSink klog writes to once LogSetter is registered.
+// It forwards records to the encoder of current log format.
+type formatSink struct {
+	setter *LogSetter
+
+	// callDepth, names and values are applied to the encoder sink on every
+	// call, as encoder can change in the meantime
+	callDepth int
+	names     []string
+	values    []interface{}
+}
+
+func (s *formatSink) current() *encoder {
+	return s.setter.encoder.Load().(*encoder)
+}
+
+func (s *formatSink) encoderSink() logr.LogSink {
+	// formatSink adds one frame between the caller and encoder sink
+	sink := withCallDepth(s.current().sink, s.callDepth+1)
+	for _, name := range s.names {
+		sink = sink.WithName(name)
+	}
+	if len(s.values) > 0 {
+		sink = sink.WithValues(s.values...)
+	}
+	return sink
+}
+
+// writeKlogBuffer receives records klog formatted as text
+func (s *formatSink) writeKlogBuffer(record []byte) {
+	s.current().write(record)
+}
+
+func (s *formatSink) Init(info logr.RuntimeInfo) {
+	// Encoder sinks are already initialized
+}
+
+func (s *formatSink) Enabled(level int) bool {
+	return s.encoderSink().Enabled(level)
+}
+
+func (s *formatSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	s.encoderSink().Info(level, msg, keysAndValues...)
+}
+
+func (s *formatSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.encoderSink().Error(err, msg, keysAndValues...)
+}
+
+func (s *formatSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	sink := *s
+	sink.values = append(append([]interface{}{}, s.values...), keysAndValues...)
+	return &sink
+}
+
+func (s *formatSink) WithName(name string) logr.LogSink {
+	sink := *s
+	sink.names = append(append([]string{}, s.names...), name)
+	return &sink
+}
+
+func (s *formatSink) WithCallDepth(depth int) logr.LogSink {
+	sink := *s
+	sink.callDepth += depth
+	return &sink
+}
+
+// textOutput writes klog text records where klog itself would: to stderr, as
+// set by logtostderr, alsologtostderr and stderrthreshold, and to klogOutput
+// unless logtostderr is set
+type textOutput struct {
+	setter *LogSetter
+}
+
+func (o *textOutput) Write(p []byte) (int, error) {
+	state := o.setter.published()
+	if state.toStderr {
+		return os.Stderr.Write(p)
+	}
+	if state.alsoToStderr || recordSeverity(p) >= state.stderrThreshold {
+		_, _ = os.Stderr.Write(p)
+	}
+	return o.setter.klogOutput.Write(p)
+}
+
+// setLogFormat switches log format of records klog writes to formatSink. In
+// JSON format, records are dropped while a quiet log level is set.
+// Must be called with mu held.
+func (l *LogSetter) setLogFormat(format v1alpha1.LogFormat) {
+	if format == "" {
+		format = v1alpha1.LogFormatText
+	}
+	if format == l.format {
+		return
+	}
+
+	switch format {
+	case v1alpha1.LogFormatJSON, v1alpha1.LogFormatText:
+	default:
+		l.logger.Info("unknown log format. Ignoring it", "format", format)
+		return
+	}
+	if !l.formatSinkSet {
+		l.logger.Info("LogSetter is not registered. Ignoring log format", "format", format)
+		return
+	}
+
+	l.logger.Info("Setting log format", "format", format)
+	if format == v1alpha1.LogFormatJSON {
+		l.encoder.Store(l.jsonEncoder)
+	} else {
+		l.encoder.Store(l.textEncoder)
+	}
+	l.format = format
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Format", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	updateFormat := func(format v1alpha1.LogFormat) {
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelInfo, Format: format},
+				},
+			},
+		})
+	}
+
+	AfterEach(func() {
+		updateFormat(v1alpha1.LogFormatText)
+		lib.SetJSONOutput(os.Stderr)
+	})
+
+	It("switches between text and JSON format at run-time", func() {
+		var buf bytes.Buffer
+		lib.SetJSONOutput(&buf)
+
+		updateFormat(v1alpha1.LogFormatJSON)
+		klog.InfoS("json record", "cluster", "production")
+		Expect(buf.String()).To(ContainSubstring(`"msg":"json record"`))
+		Expect(buf.String()).To(ContainSubstring(`"cluster":"production"`))
+
+		// Not set means text
+		updateFormat("")
+		buf.Reset()
+		klog.InfoS("text record")
+		Expect(buf.Len()).To(BeZero())
+	})
+
+	It("applies format of an entry not setting log level", func() {
+		var buf bytes.Buffer
+		lib.SetJSONOutput(&buf)
+
+		instance.SetDebugValue(lib.LogDebug)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelDebug},
+					{Component: component, Format: v1alpha1.LogFormatJSON},
+				},
+			},
+		})
+		klog.InfoS("json record")
+		Expect(buf.String()).To(ContainSubstring(`"msg":"json record"`))
+		// Entry logs at info
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+
+	It("switches format while other goroutines log", func() {
+		var buf syncBuffer
+		lib.SetJSONOutput(&buf)
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for {
+				select {
+				case <-stop:
+					return
+				case <-time.After(time.Millisecond):
+					klog.InfoS("concurrent record")
+				}
+			}
+		}()
+
+		for i := 0; i < 10; i++ {
+			updateFormat(v1alpha1.LogFormatJSON)
+			updateFormat(v1alpha1.LogFormatText)
+		}
+		close(stop)
+		Eventually(done).Should(BeClosed())
+	})
+})
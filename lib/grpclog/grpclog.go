@@ -0,0 +1,88 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpclog provides gRPC interceptors storing an elevated logger in
+// the context of requests carrying a valid debug token.
+package grpclog
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DebugTokenMetadata is the gRPC metadata key carrying the debug token
+const DebugTokenMetadata = "x-debug-token"
+
+// Setter returns the elevated logger for requests carrying a debug token.
+// It is implemented by *lib.LogSetter.
+type Setter interface {
+	RequestLogger(ctx context.Context, token string) (logr.Logger, bool)
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor which, for requests
+// carrying a valid debug token in DebugTokenMetadata, stores an elevated
+// logger in request context.
+func UnaryServerInterceptor(setter Setter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler) (interface{}, error) {
+
+		if logger, ok := setter.RequestLogger(ctx, metadataToken(ctx)); ok {
+			ctx = logr.NewContext(ctx, logger)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor which, for streams
+// carrying a valid debug token in DebugTokenMetadata, stores an elevated
+// logger in stream context.
+func StreamServerInterceptor(setter Setter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler) error {
+
+		ctx := ss.Context()
+		if logger, ok := setter.RequestLogger(ctx, metadataToken(ctx)); ok {
+			ss = &serverStream{ServerStream: ss, ctx: logr.NewContext(ctx, logger)}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// serverStream is a grpc.ServerStream with a different context
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// metadataToken returns debug token in incoming gRPC metadata, if any
+func metadataToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(DebugTokenMetadata)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpclog_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/gianlucam76/pod-log-level/lib"
+	"github.com/gianlucam76/pod-log-level/lib/grpclog"
+)
+
+var _ grpclog.Setter = &lib.LogSetter{}
+
+const token = "s3cr3t"
+
+// fakeSetter elevates requests carrying token
+type fakeSetter struct{}
+
+func (fakeSetter) RequestLogger(ctx context.Context, t string) (logr.Logger, bool) {
+	if t != token {
+		return logr.Logger{}, false
+	}
+	return funcr.New(func(prefix, args string) {}, funcr.Options{}).WithValues("debugRequest", true), true
+}
+
+// fakeStream is a grpc.ServerStream with a given context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+var _ = Describe("Interceptors", func() {
+	// elevated returns whether ctx carries a logger
+	elevated := func(ctx context.Context) bool {
+		_, err := logr.FromContext(ctx)
+		return err == nil
+	}
+
+	incoming := func(t string) context.Context {
+		return metadata.NewIncomingContext(context.TODO(), metadata.Pairs(grpclog.DebugTokenMetadata, t))
+	}
+
+	It("UnaryServerInterceptor elevates requests carrying a valid debug token", func() {
+		var debug bool
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			debug = elevated(ctx)
+			return nil, nil
+		}
+		interceptor := grpclog.UnaryServerInterceptor(fakeSetter{})
+
+		_, err := interceptor(incoming(token), nil, &grpc.UnaryServerInfo{}, handler)
+		Expect(err).To(BeNil())
+		Expect(debug).To(BeTrue())
+
+		_, err = interceptor(incoming("wrong"), nil, &grpc.UnaryServerInfo{}, handler)
+		Expect(err).To(BeNil())
+		Expect(debug).To(BeFalse())
+
+		_, err = interceptor(context.TODO(), nil, &grpc.UnaryServerInfo{}, handler)
+		Expect(err).To(BeNil())
+		Expect(debug).To(BeFalse())
+	})
+
+	It("StreamServerInterceptor elevates streams carrying a valid debug token", func() {
+		var debug bool
+		handler := func(srv interface{}, ss grpc.ServerStream) error {
+			debug = elevated(ss.Context())
+			return nil
+		}
+		interceptor := grpclog.StreamServerInterceptor(fakeSetter{})
+
+		Expect(interceptor(nil, &fakeStream{ctx: incoming(token)}, &grpc.StreamServerInfo{}, handler)).To(Succeed())
+		Expect(debug).To(BeTrue())
+
+		Expect(interceptor(nil, &fakeStream{ctx: incoming("wrong")}, &grpc.StreamServerInfo{}, handler)).To(Succeed())
+		Expect(debug).To(BeFalse())
+	})
+})
@@ -0,0 +1,230 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"flag"
+	"strconv"
+
+	"github.com/go-logr/logr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// klogFlagNames are the klog flags LogSetter overrides. logtostderr and
+// alsologtostderr are not configurable: they are turned off when needed
+// for the other flags to be effective.
+var klogFlagNames = []string{
+	"stderrthreshold",
+	"log_file",
+	"log_file_max_size",
+	"add_dir_header",
+	"one_output",
+	"logtostderr",
+	"alsologtostderr",
+}
+
+// klogFlagValues returns, for each flag set in flags, the value to set
+func klogFlagValues(flags *v1alpha1.KlogFlags) map[string]string {
+	values := make(map[string]string)
+	if flags == nil {
+		return values
+	}
+
+	if flags.StderrThreshold != nil {
+		values["stderrthreshold"] = *flags.StderrThreshold
+	}
+	if flags.LogFile != nil {
+		values["log_file"] = *flags.LogFile
+	}
+	if flags.LogFileMaxSize != nil {
+		values["log_file_max_size"] = strconv.FormatInt(*flags.LogFileMaxSize, 10)
+	}
+	if flags.AddDirHeader != nil {
+		values["add_dir_header"] = strconv.FormatBool(*flags.AddDirHeader)
+	}
+	if flags.OneOutput != nil {
+		values["one_output"] = strconv.FormatBool(*flags.OneOutput)
+	}
+
+	// With logtostderr klog writes everything to stderr only, ignoring
+	// threshold and log file
+	if flags.StderrThreshold != nil || flags.LogFile != nil {
+		values["logtostderr"] = "false"
+	}
+	return values
+}
+
+// quietThreshold returns the stderrthreshold for quiet log levels, which
+// only let warnings or errors through. It returns false for other levels.
+func quietThreshold(level v1alpha1.LogLevel) (string, bool) {
+	switch level {
+	case v1alpha1.LogLevelWarning:
+		return "WARNING", true
+	case v1alpha1.LogLevelError:
+		return "ERROR", true
+	}
+	return "", false
+}
+
+// klog severities, in increasing order
+var severities = []string{"INFO", "WARNING", "ERROR", "FATAL"}
+
+// severityIndex returns the position of severity name in severities, or 0
+// if name is not a known severity
+func severityIndex(name string) int32 {
+	for i := range severities {
+		if severities[i] == name {
+			return int32(i)
+		}
+	}
+	return 0
+}
+
+// setQuiet drops records below threshold, or stops dropping them if threshold
+// is empty. In text format, values gets the klog flags making klog only write
+// to stderr records at or above threshold, while file output is filtered by
+// klogOutput. In JSON format, records are filtered by quietSink.
+// Must be called with mu held.
+func (l *LogSetter) setQuiet(values map[string]string, threshold string) {
+	if threshold != "" {
+		values["logtostderr"] = "false"
+		values["alsologtostderr"] = "false"
+		values["stderrthreshold"] = threshold
+	}
+	l.quietSeverity = severityIndex(threshold)
+	l.klogOutput.setThreshold(severityIndex(threshold))
+}
+
+// quietSink is a LogSink which drops info records while a quiet log level
+// is set. logr has no warning records, so klog warnings, logged as info,
+// are dropped as well.
+type quietSink struct {
+	sink   logr.LogSink
+	setter *LogSetter
+}
+
+func (s *quietSink) quiet() bool {
+	return s.setter.published().quietSeverity > 0
+}
+
+func (s *quietSink) Init(info logr.RuntimeInfo) {
+	s.sink.Init(info)
+}
+
+func (s *quietSink) Enabled(level int) bool {
+	return !s.quiet() && s.sink.Enabled(level)
+}
+
+func (s *quietSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if s.quiet() {
+		return
+	}
+	s.sink.Info(level, msg, keysAndValues...)
+}
+
+func (s *quietSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+}
+
+func (s *quietSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &quietSink{sink: s.sink.WithValues(keysAndValues...), setter: s.setter}
+}
+
+func (s *quietSink) WithName(name string) logr.LogSink {
+	return &quietSink{sink: s.sink.WithName(name), setter: s.setter}
+}
+
+func (s *quietSink) WithCallDepth(depth int) logr.LogSink {
+	return &quietSink{sink: withCallDepth(s.sink, depth), setter: s.setter}
+}
+
+// setKlogOutput makes klogOutput write to the current log_file, or discard
+// records if none is set. Files in log_dir are not written: with a logger set,
+// klog leaves output to it.
+// Must be called with mu held.
+func (l *LogSetter) setKlogOutput() {
+	maxSize, err := strconv.ParseUint(flagValue("log_file_max_size"), 10, 64)
+	if err != nil {
+		maxSize = 0
+	}
+	if err := l.klogOutput.setFile(flagValue("log_file"), maxSize*1024*1024); err != nil {
+		l.logger.Error(err, "unable to open log file", "file", flagValue("log_file"))
+	}
+}
+
+// stderrFlags returns the values of the klog flags deciding which text
+// records are written to stderr. Flags not registered have klog defaults.
+func stderrFlags() (toStderr, alsoToStderr bool, threshold int32) {
+	toStderr, err := strconv.ParseBool(flagValue("logtostderr"))
+	if err != nil {
+		toStderr = true
+	}
+	alsoToStderr, _ = strconv.ParseBool(flagValue("alsologtostderr"))
+	threshold = severityIndex("ERROR")
+	if value, err := strconv.Atoi(flagValue("stderrthreshold")); err == nil {
+		threshold = int32(value)
+	}
+	return toStderr, alsoToStderr, threshold
+}
+
+// flagValue returns the value of flag name, or an empty string if flag is
+// not defined
+func flagValue(name string) string {
+	f := flag.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// setKlogFlags overrides klog flags with values. Original value of a flag is
+// recorded the first time it is overridden, and restored once values does not
+// set it anymore. Must be called with mu held.
+func (l *LogSetter) setKlogFlags(values map[string]string) {
+	for _, name := range klogFlagNames {
+		f := flag.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			original, overridden := l.originalKlogFlags[name]
+			if !overridden {
+				continue
+			}
+			l.logger.Info("Restoring klog flag", "flag", name, "value", original)
+			if err := f.Value.Set(original); err != nil {
+				l.logger.Error(err, "unable to restore klog flag", "flag", name)
+			}
+			delete(l.originalKlogFlags, name)
+			continue
+		}
+
+		if _, overridden := l.originalKlogFlags[name]; !overridden {
+			l.originalKlogFlags[name] = f.Value.String()
+		}
+		if f.Value.String() == value {
+			continue
+		}
+		l.logger.Info("Setting klog flag", "flag", name, "value", value)
+		if err := f.Value.Set(value); err != nil {
+			l.logger.Error(err, "unable to set klog flag", "flag", name)
+		}
+	}
+}
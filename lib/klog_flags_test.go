@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("KlogFlags", func() {
+	It("overrides klog flags and restores them when entry is removed", func() {
+		names := []string{"stderrthreshold", "log_file", "log_file_max_size", "add_dir_header", "one_output"}
+		originals := make(map[string]string)
+		for _, name := range names {
+			f := flag.Lookup(name)
+			Expect(f).ToNot(BeNil())
+			originals[name] = f.Value.String()
+		}
+
+		logFile := filepath.Join(GinkgoT().TempDir(), "component.log")
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelInfo,
+						KlogFlags: &v1alpha1.KlogFlags{
+							StderrThreshold: pointer.String("ERROR"),
+							LogFile:         pointer.String(logFile),
+							LogFileMaxSize:  pointer.Int64(100),
+							AddDirHeader:    pointer.Bool(true),
+							OneOutput:       pointer.Bool(true),
+						},
+					},
+				},
+			},
+		}
+
+		lib.UpdateLogLevel(conf)
+		Expect(flag.Lookup("stderrthreshold").Value.String()).To(Equal("2"))
+		Expect(flag.Lookup("log_file").Value.String()).To(Equal(logFile))
+		Expect(flag.Lookup("log_file_max_size").Value.String()).To(Equal("100"))
+		Expect(flag.Lookup("add_dir_header").Value.String()).To(Equal("true"))
+		Expect(flag.Lookup("one_output").Value.String()).To(Equal("true"))
+		Expect(flag.Lookup("logtostderr").Value.String()).To(Equal("false"))
+
+		// Flags no longer set go back to their original value
+		conf.Spec.Configuration[0].KlogFlags = &v1alpha1.KlogFlags{OneOutput: pointer.Bool(true)}
+		lib.UpdateLogLevel(conf)
+		Expect(flag.Lookup("stderrthreshold").Value.String()).To(Equal(originals["stderrthreshold"]))
+		Expect(flag.Lookup("add_dir_header").Value.String()).To(Equal(originals["add_dir_header"]))
+		Expect(flag.Lookup("one_output").Value.String()).To(Equal("true"))
+
+		// Removing the entry restores all flags
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+		for _, name := range names {
+			Expect(flag.Lookup(name).Value.String()).To(Equal(originals[name]))
+		}
+	})
+
+	It("writes to the log file currently set", func() {
+		originalToStderr := flag.Lookup("logtostderr").Value.String()
+		dir := GinkgoT().TempDir()
+		firstFile := filepath.Join(dir, "first.log")
+		secondFile := filepath.Join(dir, "second.log")
+		content := func(file string) string {
+			klog.Flush()
+			data, err := os.ReadFile(file)
+			if os.IsNotExist(err) {
+				return ""
+			}
+			Expect(err).To(BeNil())
+			return string(data)
+		}
+
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelInfo,
+						KlogFlags: &v1alpha1.KlogFlags{LogFile: pointer.String(firstFile)},
+					},
+				},
+			},
+		}
+
+		lib.UpdateLogLevel(conf)
+		klog.Info("first record")
+		Expect(content(firstFile)).To(ContainSubstring("first record"))
+
+		conf.Spec.Configuration[0].KlogFlags.LogFile = pointer.String(secondFile)
+		lib.UpdateLogLevel(conf)
+		klog.Info("second record")
+		Expect(content(secondFile)).To(ContainSubstring("second record"))
+		Expect(content(firstFile)).ToNot(ContainSubstring("second record"))
+
+		// Without a log file, file output is discarded
+		conf.Spec.Configuration[0].KlogFlags = &v1alpha1.KlogFlags{StderrThreshold: pointer.String("ERROR")}
+		lib.UpdateLogLevel(conf)
+		Expect(flag.Lookup("logtostderr").Value.String()).To(Equal("false"))
+		klog.Info("third record")
+		Expect(content(firstFile)).ToNot(ContainSubstring("third record"))
+		Expect(content(secondFile)).ToNot(ContainSubstring("third record"))
+
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+		Expect(flag.Lookup("logtostderr").Value.String()).To(Equal(originalToStderr))
+	})
+})
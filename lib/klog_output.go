@@ -0,0 +1,128 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+// klogOutput receives klog text records not written to stderr only. Unlike
+// klog, which opens its log file once, klogOutput follows log_file changes. Records go to file, or are discarded if no file
+// is set or they are below threshold.
+type klogOutput struct {
+	mu sync.Mutex
+
+	// threshold is the index, in severities, of the lowest severity written
+	threshold int32
+
+	// path is the file records are written to
+	path string
+
+	// maxBytes is the size at which file is truncated. 0 means no limit.
+	maxBytes uint64
+
+	file  *os.File
+	bytes uint64
+}
+
+// setFile makes output go to path, truncated once it reaches maxBytes.
+// An empty path discards output.
+func (o *klogOutput) setFile(path string, maxBytes uint64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.maxBytes = maxBytes
+	if path == o.path {
+		return nil
+	}
+
+	o.close()
+	o.path = path
+	if path == "" {
+		return nil
+	}
+	return o.open(os.O_APPEND)
+}
+
+// setThreshold drops records with severity below threshold
+func (o *klogOutput) setThreshold(threshold int32) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.threshold = threshold
+}
+
+// open opens file at path. Must be called with mu held.
+func (o *klogOutput) open(flag int) error {
+	file, err := os.OpenFile(o.path, os.O_WRONLY|os.O_CREATE|flag, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	o.file = file
+	o.bytes = uint64(info.Size())
+	return nil
+}
+
+// close closes current file, if any. Must be called with mu held.
+func (o *klogOutput) close() {
+	if o.file != nil {
+		o.file.Close()
+		o.file = nil
+	}
+	o.bytes = 0
+}
+
+func (o *klogOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.file == nil || recordSeverity(p) < o.threshold {
+		return io.Discard.Write(p)
+	}
+
+	// Same as klog, file starts over once it reaches maximum size
+	if o.maxBytes > 0 && o.bytes+uint64(len(p)) >= o.maxBytes {
+		o.close()
+		if err := o.open(os.O_TRUNC); err != nil {
+			return 0, err
+		}
+	}
+	n, err := o.file.Write(p)
+	o.bytes += uint64(n)
+	return n, err
+}
+
+// recordSeverity returns the index, in severities, of the severity of a klog
+// text record, which is the first letter of its header. Records without
+// header are considered errors, so they are never dropped.
+func recordSeverity(record []byte) int32 {
+	if len(record) > 0 {
+		for i := range severities {
+			if record[0] == severities[i][0] {
+				return int32(i)
+			}
+		}
+	}
+	return severityIndex("ERROR")
+}
@@ -20,23 +20,39 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 
 	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
 )
 
+const (
+	// logSettingResource is the LogSetting resource watched
+	logSettingResource = "logsettings.v1alpha1.open.projectsveltos.io"
+
+	// logSettingName is the name of the LogSetting instance
+	logSettingName = "default"
+)
+
 // Following are log severity levels to be used by registered services
 const (
 	// LogInfo is the info level
@@ -56,6 +72,10 @@ type LogSetter struct {
 
 	logger logr.Logger
 
+	// componentLogger is the logger of the component, which loggers returned
+	// by LoggerFor are based on
+	componentLogger logr.Logger
+
 	// Setting to severity to Info corresponds to V(0).
 	// Use SetInfoValue to set a different severity for info
 	infoValue string
@@ -72,6 +92,102 @@ type LogSetter struct {
 	component v1alpha1.Component
 
 	config *rest.Config
+
+	// clientset is created from config on first use. See kubernetesClient.
+	clientsetOnce sync.Once
+	clientset     kubernetes.Interface
+	clientsetErr  error
+
+	// mu guards LogSetter state and serializes log severity changes.
+	// logger, component and config never change once LogSetter is created.
+	mu sync.Mutex
+
+	// logSetting is the last LogSetting instance processed
+	logSetting *v1alpha1.LogSetting
+
+	// configuration is the LogSetting entry currently applied to this
+	// component, if any. All its fields are taken from the same entry.
+	configuration *v1alpha1.ComponentConfiguration
+
+	// scheduleTimer re-evaluates configuration when a schedule opens or closes
+	scheduleTimer *time.Timer
+
+	// errorsMu guards errorTimes. Errors are counted without taking mu, as
+	// mu is held while the LogSetter itself logs.
+	errorsMu sync.Mutex
+
+	// errorTimes contains when errors were logged within the escalation window
+	errorTimes []time.Time
+
+	// escalated is true while log severity is raised because of an error burst
+	escalated bool
+
+	// recorders are the flight recorders in use for this component
+	recorders []*flightRecorder
+
+	// dumpTrigger is the last seen dump trigger. Nil till a LogSetting is processed.
+	dumpTrigger *string
+
+	// sampling is the sampling policy for records above Info, if any
+	sampling *v1alpha1.Sampling
+
+	// scheme is used to find GroupVersionKind of objects passed to LoggerFor
+	// Use SetScheme to register additional types
+	scheme *runtime.Scheme
+
+	// debugToken is the cached request debug token
+	debugToken *debugToken
+
+	// format is the log format currently set
+	format v1alpha1.LogFormat
+
+	// originalKlogFlags contains the original value of overridden klog flags
+	originalKlogFlags map[string]string
+
+	// quietSeverity is the index, in severities, of the lowest severity
+	// logged. 0 unless a quiet log level is set.
+	quietSeverity int32
+
+	// state holds the *loggingState last published
+	state atomic.Value
+
+	// klogOutput receives klog text records not written to stderr only
+	klogOutput klogOutput
+
+	// formatSinkSet is true once klog writes to a formatSink, which then
+	// forwards records to encoder, either textEncoder or jsonEncoder
+	formatSinkSet bool
+	encoder       atomic.Value
+	textEncoder   *encoder
+	jsonEncoder   *encoder
+
+	// persistencePath is where last LogSetting processed is persisted, if set
+	persistencePath string
+
+	// restored is true from when a persisted LogSetting is restored till
+	// the informer syncs
+	restored bool
+
+	// podLevel is the log level set by Pod annotation, if any
+	podLevel v1alpha1.LogLevel
+
+	// signalLevel is the log level set by SIGUSR1, if any. Reset by SIGUSR2.
+	signalLevel v1alpha1.LogLevel
+
+	// level is the log level currently set, NotSet for default
+	level v1alpha1.LogLevel
+
+	// tags the component is registered with
+	tags labels.Set
+
+	// nodeName is the node the component runs on, if known
+	nodeName string
+
+	// nodeLabels are the labels of node nodeName
+	nodeLabels labels.Set
+
+	// replica is the name of the Pod the component runs in, if known
+	replica string
 }
 
 var (
@@ -79,40 +195,90 @@ var (
 	once     sync.Once
 )
 
-func newInstance(component v1alpha1.Component, config *rest.Config, logger logr.Logger) *LogSetter {
+func newInstance(component v1alpha1.Component, config *rest.Config,
+	logger, componentLogger logr.Logger) *LogSetter {
 	once.Do(func() {
 		logger.Info("Creating LogSetter instance")
+		// Unless Pod has a different hostname, this is Pod name
+		replica, _ := os.Hostname()
 		instance = &LogSetter{
-			logger:       logger,
-			defaultValue: strconv.Itoa(LogInfo),
-			infoValue:    strconv.Itoa(LogInfo),
-			debugValue:   strconv.Itoa(LogDebug),
-			verboseValue: strconv.Itoa(LogVerbose),
-			component:    component,
-			config:       config,
+			logger:          logger,
+			componentLogger: componentLogger,
+			defaultValue:    strconv.Itoa(LogInfo),
+			infoValue:       strconv.Itoa(LogInfo),
+			debugValue:      strconv.Itoa(LogDebug),
+			verboseValue:    strconv.Itoa(LogVerbose),
+			component:       component,
+			config:          config,
+			scheme:          clientgoscheme.Scheme,
+			format:          v1alpha1.LogFormatText,
+			podLevel:        v1alpha1.LogLevelNotSet,
+			signalLevel:     v1alpha1.LogLevelNotSet,
+			replica:         replica,
+
+			originalKlogFlags: make(map[string]string),
 		}
+		instance.publish()
 	})
 	return instance
 }
 
-// SetDefaultValue sets default severity
+// SetDefaultValue sets default severity. Current configuration is applied
+// again, so the change takes effect immediately.
 func (l *LogSetter) SetDefaultValue(defaultSeverity int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.defaultValue = strconv.Itoa(defaultSeverity)
+	l.reapply()
 }
 
-// SetInfoValue sets severity for Info
+// SetInfoValue sets severity for Info. Current configuration is applied
+// again, so the change takes effect immediately.
 func (l *LogSetter) SetInfoValue(infoSeverity int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.infoValue = strconv.Itoa(infoSeverity)
+	l.reapply()
 }
 
-// SetDebugValue sets severity for Debug
+// SetDebugValue sets severity for Debug. Current configuration is applied
+// again, so the change takes effect immediately.
 func (l *LogSetter) SetDebugValue(debugSeverity int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.debugValue = strconv.Itoa(debugSeverity)
+	l.reapply()
 }
 
-// SetVerboseValue sets severity for Verbose
+// SetVerboseValue sets severity for Verbose. Current configuration is applied
+// again, so the change takes effect immediately.
 func (l *LogSetter) SetVerboseValue(verboseSeverity int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
 	l.verboseValue = strconv.Itoa(verboseSeverity)
+	l.reapply()
+	l.publish()
+}
+
+// SetScheme sets the scheme used to find GroupVersionKind of objects passed to
+// LoggerFor, when those do not have TypeMeta set
+func (l *LogSetter) SetScheme(scheme *runtime.Scheme) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.scheme = scheme
+}
+
+// reapply sets log severity again based on the last LogSetting processed, if
+// any. Must be called with mu held.
+func (l *LogSetter) reapply() {
+	if l.logSetting != nil {
+		l.updateLogLevel(l.logSetting)
+	}
 }
 
 // GetInstance returns LogSetter instance
@@ -131,17 +297,29 @@ func RegisterForLogSettings(
 	componentNamespace, componentIdentifier string,
 	logger logr.Logger,
 	config *rest.Config,
+	opts ...Option,
 ) *LogSetter {
 
+	o := newOptions(opts)
+	componentLogger := logger
+	if o.logger != nil {
+		logger = *o.logger
+	}
+
 	logger.Info("Registering for run-time log severity changes", "component",
 		fmt.Sprintf("%s/%s", componentNamespace, componentIdentifier))
 	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
-	newInstance(component, config, logger)
+	newInstance(component, config, logger, componentLogger)
+
+	instance.applyOptions(ctx, o, getLogSetting)
+	var signals chan os.Signal
+	if o.signalHandlers {
+		signals = notifySignals()
+	}
+	instance.runWatchers(ctx, o, signals)
 
 	// dynamic informer needs to be told which type to watch
-	dcinformer, err := getDynamicInformer(
-		"logsettings.v1alpha1.open.projectsveltos.io",
-	)
+	dcinformer, err := getDynamicInformer(logSettingResource)
 	if err != nil {
 		logger.Error(err, "Failed to get informer")
 	}
@@ -149,6 +327,102 @@ func RegisterForLogSettings(
 	return instance
 }
 
+// applyOptions sets log severity, as required by o, before registration
+// returns. get fetches LogSetting from the API server.
+func (l *LogSetter) applyOptions(ctx context.Context, o *options,
+	get func(ctx context.Context) (*v1alpha1.LogSetting, error)) {
+
+	l.installFormatSink(o.klogLogger)
+	if o.tags != nil {
+		l.setTags(o.tags)
+	}
+	if o.nodeName != "" {
+		l.setNode(o.nodeName)
+	}
+	if o.podName != "" {
+		l.setReplica(o.podName)
+	}
+	if o.persistencePath != "" {
+		l.restorePersisted(o.persistencePath)
+	}
+	if o.initialFetchTimeout != 0 {
+		l.applyInitialLogSetting(ctx, o.initialFetchTimeout, get)
+	}
+	if o.annotationsFile != "" {
+		l.readAnnotationsFile(o.annotationsFile)
+	}
+}
+
+// runWatchers starts the watchers enabled by o, which run till ctx is done.
+// signals, if not nil, receives signals changing log severity.
+func (l *LogSetter) runWatchers(ctx context.Context, o *options, signals chan os.Signal) {
+	if signals != nil {
+		go l.handleSignals(ctx, signals)
+	}
+	if o.annotationsFile != "" {
+		go l.pollAnnotationsFile(ctx, o.annotationsFile, annotationsFilePollInterval)
+	}
+	if o.podName == "" && o.nodeName == "" {
+		return
+	}
+
+	clientset, err := l.kubernetesClient()
+	if err != nil {
+		l.logger.Error(err, "Failed to get clientset. Pod annotations and Node labels are not watched")
+		return
+	}
+	if o.podName != "" {
+		l.watchPod(ctx, clientset, o.podNamespace, o.podName)
+	}
+	if o.nodeName != "" {
+		l.watchNode(ctx, clientset, o.nodeName)
+	}
+}
+
+// kubernetesClient returns the clientset used by LogSetter, created on first use
+func (l *LogSetter) kubernetesClient() (kubernetes.Interface, error) {
+	l.clientsetOnce.Do(func() {
+		l.clientset, l.clientsetErr = kubernetes.NewForConfig(l.config)
+	})
+	return l.clientset, l.clientsetErr
+}
+
+// getLogSetting fetches LogSetting instance using a dynamic client
+func getLogSetting(ctx context.Context) (*v1alpha1.LogSetting, error) {
+	dc, err := dynamic.NewForConfig(instance.config)
+	if err != nil {
+		return nil, err
+	}
+	gvr, _ := schema.ParseResourceArg(logSettingResource)
+	u, err := dc.Resource(*gvr).Get(ctx, logSettingName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	d := &v1alpha1.LogSetting{}
+	err = runtime.DefaultUnstructuredConverter.FromUnstructured(u.UnstructuredContent(), d)
+	return d, err
+}
+
+// applyInitialLogSetting fetches LogSetting with get, waiting at most timeout,
+// and sets log severity accordingly. On failure default log severity is kept.
+func (l *LogSetter) applyInitialLogSetting(ctx context.Context, timeout time.Duration,
+	get func(ctx context.Context) (*v1alpha1.LogSetting, error)) {
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	d, err := get(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			l.logger.Info("LogSetting not found. Using default log severity")
+			return
+		}
+		l.logger.Error(err, "Failed to fetch LogSetting. Using default log severity")
+		return
+	}
+	UpdateLogLevel(d)
+}
+
 func getDynamicInformer(resourceType string) (informers.GenericInformer, error) {
 	// Grab a dynamic interface that we can create informers from
 	dc, err := dynamic.NewForConfig(instance.config)
@@ -172,12 +446,33 @@ func runLogSettingsInformer(
 	s cache.SharedIndexInformer,
 ) {
 
-	handlers := cache.ResourceEventHandlerFuncs{
+	handlers := logSettingEventHandlers(func(obj interface{}) (*v1alpha1.LogSetting, error) {
+		d := &v1alpha1.LogSetting{}
+		err := runtime.DefaultUnstructuredConverter.
+			FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), d)
+		return d, err
+	})
+	_, err := s.AddEventHandler(handlers)
+	if err != nil {
+		panic(1)
+	}
+	go func() {
+		if cache.WaitForCacheSync(stopCh, s.HasSynced) {
+			instance.logSettingSynced(len(s.GetStore().List()) != 0)
+		}
+	}()
+	s.Run(stopCh)
+}
+
+// logSettingEventHandlers returns the handlers processing LogSetting events.
+// toLogSetting converts objects delivered by the informer.
+func logSettingEventHandlers(toLogSetting func(obj interface{}) (*v1alpha1.LogSetting, error),
+) cache.ResourceEventHandlerFuncs {
+
+	return cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			instance.logger.Info("got add notification for LogSettings")
-			d := &v1alpha1.LogSetting{}
-			err := runtime.DefaultUnstructuredConverter.
-				FromUnstructured(obj.(*unstructured.Unstructured).UnstructuredContent(), d)
+			d, err := toLogSetting(obj)
 			if err != nil {
 				instance.logger.Error(err, "could not convert obj to LogSettings")
 				return
@@ -185,20 +480,12 @@ func runLogSettingsInformer(
 			UpdateLogLevel(d)
 		},
 		DeleteFunc: func(obj interface{}) {
-			instance.logger.Info(
-				"LogSettings is deleted. Setting log severity to info",
-				"default",
-				instance.defaultValue,
-			)
-			if err := flag.Lookup("v").Value.Set(instance.defaultValue); err != nil {
-				instance.logger.Error(err, "unable to set default level")
-			}
+			instance.logger.Info("LogSettings is deleted. Setting log severity to default")
+			UpdateLogLevel(&v1alpha1.LogSetting{})
 		},
 		UpdateFunc: func(oldObj, newObj interface{}) {
 			instance.logger.Info("got update notification for LogSettings")
-			d := &v1alpha1.LogSetting{}
-			err := runtime.DefaultUnstructuredConverter.
-				FromUnstructured(newObj.(*unstructured.Unstructured).UnstructuredContent(), d)
+			d, err := toLogSetting(newObj)
 			if err != nil {
 				instance.logger.Error(err, "could not convert obj to LogSettings")
 				return
@@ -206,11 +493,50 @@ func runLogSettingsInformer(
 			UpdateLogLevel(d)
 		},
 	}
-	_, err := s.AddEventHandler(handlers)
+}
+
+// stopScheduleTimer stops any pending schedule re-evaluation. Must be called
+// with mu held.
+func (l *LogSetter) stopScheduleTimer() {
+	if l.scheduleTimer != nil {
+		l.scheduleTimer.Stop()
+		l.scheduleTimer = nil
+	}
+}
+
+// isScheduled returns whether schedule is active at now. It also updates
+// nextChange if schedule changes state before it.
+func (l *LogSetter) isScheduled(schedule *v1alpha1.Schedule, now time.Time, nextChange *time.Time) bool {
+	active, next, err := evaluateSchedule(schedule, now)
 	if err != nil {
-		panic(1)
+		l.logger.Error(err, "invalid schedule. Ignoring configuration")
+		return false
 	}
-	s.Run(stopCh)
+
+	if nextChange.IsZero() || next.Before(*nextChange) {
+		*nextChange = next
+	}
+	return active
+}
+
+// scheduleReevaluation makes LogSetting be processed again at nextChange, when
+// a schedule opens or closes. Must be called with mu held.
+func (l *LogSetter) scheduleReevaluation(d *v1alpha1.LogSetting, now, nextChange time.Time) {
+	l.stopScheduleTimer()
+	if nextChange.IsZero() {
+		return
+	}
+
+	l.logger.Info("Log severity will be reevaluated", "time", nextChange)
+	l.scheduleTimer = time.AfterFunc(nextChange.Sub(now), func() {
+		l.mu.Lock()
+		current := l.logSetting
+		l.mu.Unlock()
+		// A newer LogSetting has already been processed
+		if current == d {
+			UpdateLogLevel(d)
+		}
+	})
 }
 
 // UpdateLogLevel updates log severity
@@ -218,35 +544,201 @@ func UpdateLogLevel(
 	d *v1alpha1.LogSetting,
 ) {
 
-	found := false
-	for _, c := range d.Spec.Configuration {
-		if instance.component == c.Component {
-			if c.LogLevel == v1alpha1.LogLevelVerbose {
-				found = true
-				instance.logger.Info("Setting log severity to verbose", "verbose", instance.verboseValue)
-				if err := flag.Lookup("v").Value.Set(instance.verboseValue); err != nil {
-					instance.logger.Error(err, "unable to set log level")
-				}
-			} else if c.LogLevel == v1alpha1.LogLevelDebug {
-				found = true
-				instance.logger.Info("Setting log severity to debug", "debug", instance.debugValue)
-				if err := flag.Lookup("v").Value.Set(instance.debugValue); err != nil {
-					instance.logger.Error(err, "unable to set log level")
-				}
-			} else if c.LogLevel == v1alpha1.LogLevelInfo {
-				found = true
-				instance.logger.Info("Setting log severity to info", "info", instance.infoValue)
-				if err := flag.Lookup("v").Value.Set(instance.infoValue); err != nil {
-					instance.logger.Error(err, "unable to set log level")
-				}
-			}
+	instance.mu.Lock()
+	defer instance.mu.Unlock()
+
+	instance.updateLogLevel(d)
+}
+
+// updateLogLevel sets log severity based on LogSetting configuration.
+// Must be called with mu held.
+func (l *LogSetter) updateLogLevel(
+	d *v1alpha1.LogSetting,
+) {
+
+	// Keep a private copy: callers can modify d once processed
+	if d != l.logSetting {
+		d = d.DeepCopy()
+	}
+	l.logSetting = d
+	now := time.Now()
+	var nextChange time.Time
+
+	severity, key, value := "info", "default", l.defaultValue
+	level := v1alpha1.LogLevelNotSet
+	// Most specific entry currently scheduled, and with a valid log level,
+	// applies. All schedules are evaluated so that next change is known.
+	l.configuration = nil
+	for _, m := range Resolve(d, l.target()) {
+		c := &d.Spec.Configuration[m.Index]
+		if c.Schedule != nil && !l.isScheduled(c.Schedule, now, &nextChange) {
+			continue
+		}
+		if l.configuration != nil {
+			continue
+		}
+		if entryLevel := EntryLogLevel(c); IsValidLogLevel(&d.Spec, entryLevel) {
+			severity, value, _ = l.logLevelValue(c, entryLevel)
+			key = severity
+			level = entryLevel
+			l.configuration = c
+		}
+	}
+
+	// Level set by Pod annotation takes precedence while present
+	if l.podLevel != v1alpha1.LogLevelNotSet {
+		if s, v, ok := l.logLevelValue(l.configuration, l.podLevel); ok {
+			severity, key, value = s, "annotation", v
+			level = l.podLevel
 		}
 	}
 
-	if !found {
-		instance.logger.Info("Setting log severity to info", "default", instance.defaultValue)
-		if err := flag.Lookup("v").Value.Set(instance.defaultValue); err != nil {
-			instance.logger.Error(err, "unable to set default level")
+	// Level set by signal takes precedence till it is reset
+	if l.signalLevel != v1alpha1.LogLevelNotSet {
+		if s, v, ok := l.logLevelValue(l.configuration, l.signalLevel); ok {
+			severity, key, value = s, "signal", v
+			level = l.signalLevel
 		}
 	}
+
+	l.level = level
+	l.setLogSeverity(severity, key, value)
+	l.scheduleReevaluation(d, now, nextChange)
+
+	dumpTrigger := ""
+	format := v1alpha1.LogFormatText
+	var klogFlags *v1alpha1.KlogFlags
+	l.sampling = nil
+	if configuration := l.configuration; configuration != nil {
+		dumpTrigger = configuration.DumpTrigger
+		format = configuration.Format
+		klogFlags = configuration.KlogFlags
+		l.sampling = configuration.Sampling
+	}
+	l.setLogFormat(format)
+
+	flagValues := klogFlagValues(klogFlags)
+	// While an error escalation is in progress, log severity is never quiet
+	threshold, ok := quietThreshold(level)
+	if !ok || l.escalated {
+		threshold = ""
+	}
+	l.setQuiet(flagValues, threshold)
+	l.setKlogFlags(flagValues)
+	l.setKlogOutput()
+	l.publish()
+	l.checkDumpTrigger(dumpTrigger)
+	l.persist(d)
+}
+
+// loggingState is the LogSetter state loggers read on every call. A new
+// instance is published every time that state changes, so that logging
+// never waits for mu.
+type loggingState struct {
+	// recordedValue is the highest klog verbosity flight recorders keep
+	recordedValue int
+
+	// sampling is the sampling policy for records above Info, if any
+	sampling *v1alpha1.Sampling
+
+	// quietSeverity is the index, in severities, of the lowest severity logged
+	quietSeverity int32
+
+	// toStderr, alsoToStderr and stderrThreshold are the values of klog
+	// flags deciding which text records are written to stderr
+	toStderr        bool
+	alsoToStderr    bool
+	stderrThreshold int32
+
+	// escalation is the error escalation policy of the applied entry, if any
+	escalation *v1alpha1.ErrorEscalation
+
+	// escalated is true while log severity is raised because of errors
+	escalated bool
+}
+
+// publish makes current state visible to loggers. Must be called with mu held.
+func (l *LogSetter) publish() {
+	state := &loggingState{
+		recordedValue: l.recordedValue(),
+		sampling:      l.sampling,
+		quietSeverity: l.quietSeverity,
+		escalated:     l.escalated,
+	}
+	state.toStderr, state.alsoToStderr, state.stderrThreshold = stderrFlags()
+	if l.configuration != nil {
+		state.escalation = l.configuration.ErrorEscalation
+	}
+	l.state.Store(state)
+}
+
+// published returns the state last published
+func (l *LogSetter) published() *loggingState {
+	return l.state.Load().(*loggingState)
+}
+
+// logLevelValue returns severity name and klog verbosity for level, which is
+// either a built-in level or a custom one declared in current LogSetting. Mappings
+// in current LogSetting, for the component configured by configuration (which
+// can be nil), take precedence over values set by the component. It returns
+// false if level does not set a severity. Must be called with mu held.
+func (l *LogSetter) logLevelValue(configuration *v1alpha1.ComponentConfiguration,
+	level v1alpha1.LogLevel) (severity, value string, ok bool) {
+
+	switch level {
+	case v1alpha1.LogLevelVerbose:
+		severity, value = "verbose", l.verboseValue
+	case v1alpha1.LogLevelDebug:
+		severity, value = "debug", l.debugValue
+	case v1alpha1.LogLevelInfo:
+		severity, value = "info", l.infoValue
+	case v1alpha1.LogLevelWarning:
+		severity, value = "warning", l.infoValue
+	case v1alpha1.LogLevelError:
+		severity, value = "error", l.infoValue
+	default:
+		// Custom levels are not affected by mappings
+		if l.logSetting == nil {
+			return "", "", false
+		}
+		customLevel := findCustomLevel(&l.logSetting.Spec, level)
+		if customLevel == nil {
+			return "", "", false
+		}
+		return customLevel.Name, strconv.Itoa(int(customLevel.Verbosity)), true
+	}
+
+	var spec *v1alpha1.LogSettingSpec
+	if l.logSetting != nil {
+		spec = &l.logSetting.Spec
+	}
+	if v, mapped := ResolveVerbosity(spec, configuration, level); mapped {
+		value = strconv.Itoa(int(v))
+	}
+	return severity, value, true
+}
+
+// setLogSeverity sets klog verbosity. While an error escalation is in progress,
+// verbosity is never set below debug. Must be called with mu held.
+func (l *LogSetter) setLogSeverity(severity, key, value string) {
+	if l.escalated {
+		_, debugValue, _ := l.logLevelValue(l.configuration, v1alpha1.LogLevelDebug)
+		if verbosity(value) < verbosity(debugValue) {
+			severity, key, value = "debug", "escalation", debugValue
+		}
+	}
+
+	l.logger.Info(fmt.Sprintf("Setting log severity to %s", severity), key, value)
+	if err := flag.Lookup("v").Value.Set(value); err != nil {
+		l.logger.Error(err, "unable to set log level")
+	}
+}
+
+// verbosity converts a klog verbosity value to int
+func verbosity(value string) int {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return v
 }
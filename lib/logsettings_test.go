@@ -32,6 +32,13 @@ import (
 var _ = Describe("LogSetting", func() {
 	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
 
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
 	It("change klog level appropriately", func() {
 		conf := &v1alpha1.LogSetting{
 			ObjectMeta: metav1.ObjectMeta{
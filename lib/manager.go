@@ -0,0 +1,142 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"k8s.io/apimachinery/pkg/types"
+	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// HealthCheckName is the name of the healthz and readyz checks added by
+// SetupWithManager
+const HealthCheckName = "log-setting"
+
+// SetupWithManager registers component for run-time log severity changes
+// using the shared cache of mgr, instead of a dedicated informer.
+// LogSetting types must be added to the manager scheme.
+// A healthz and a readyz check, named HealthCheckName, fail till LogSetting
+// cache is synced.
+func SetupWithManager(mgr manager.Manager, component v1alpha1.Component, opts ...Option,
+) (*LogSetter, error) {
+
+	o := newOptions(opts)
+	logger := mgr.GetLogger().WithName("log-setter")
+	if o.logger != nil {
+		logger = *o.logger
+	}
+
+	logger.Info("Registering for run-time log severity changes", "component",
+		fmt.Sprintf("%s/%s", component.Namespace, component.Identifier))
+	newInstance(component, mgr.GetConfig(), logger, mgr.GetLogger())
+
+	// Manager cache is not started yet: LogSetting is read from API server
+	instance.applyOptions(context.TODO(), o, func(ctx context.Context) (*v1alpha1.LogSetting, error) {
+		d := &v1alpha1.LogSetting{}
+		err := mgr.GetAPIReader().Get(ctx, types.NamespacedName{Name: logSettingName}, d)
+		return d, err
+	})
+
+	informer, err := mgr.GetCache().GetInformer(context.TODO(), &v1alpha1.LogSetting{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LogSetting informer: %w", err)
+	}
+
+	handlers := logSettingEventHandlers(func(obj interface{}) (*v1alpha1.LogSetting, error) {
+		d, ok := obj.(*v1alpha1.LogSetting)
+		if !ok {
+			return nil, fmt.Errorf("unexpected type %T", obj)
+		}
+		return d, nil
+	})
+	if _, err := informer.AddEventHandler(handlers); err != nil {
+		return nil, fmt.Errorf("failed to add LogSetting event handler: %w", err)
+	}
+
+	runnable := &logSettingRunnable{setter: instance, cache: mgr.GetCache(), options: o}
+	if o.signalHandlers {
+		// Handle signals right away, they are processed once manager starts
+		runnable.signals = notifySignals()
+	}
+	if err := mgr.Add(runnable); err != nil {
+		return nil, err
+	}
+
+	checker := func(_ *http.Request) error {
+		if !informer.HasSynced() {
+			return errors.New("LogSetting cache is not synced")
+		}
+		return nil
+	}
+	if err := mgr.AddHealthzCheck(HealthCheckName, checker); err != nil {
+		return nil, err
+	}
+	if err := mgr.AddReadyzCheck(HealthCheckName, checker); err != nil {
+		return nil, err
+	}
+
+	return instance, nil
+}
+
+// logSettingRunnable runs the watchers enabled by options and waits for
+// LogSetting cache to be synced, so that live LogSetting takes over any
+// persisted one. Once the manager stops, it stops pending schedule
+// re-evaluations.
+type logSettingRunnable struct {
+	setter  *LogSetter
+	cache   ctrlcache.Cache
+	options *options
+
+	// signals, if not nil, receives signals changing log severity
+	signals chan os.Signal
+}
+
+// Start implements manager.Runnable
+func (r *logSettingRunnable) Start(ctx context.Context) error {
+	r.setter.runWatchers(ctx, r.options, r.signals)
+
+	if r.cache.WaitForCacheSync(ctx) {
+		r.setter.logger.Info("LogSetting cache synced")
+		logSettings := &v1alpha1.LogSettingList{}
+		if err := r.cache.List(ctx, logSettings); err != nil {
+			r.setter.logger.Error(err, "Failed to list LogSettings")
+		} else {
+			r.setter.logSettingSynced(len(logSettings.Items) != 0)
+		}
+	}
+
+	<-ctx.Done()
+
+	r.setter.mu.Lock()
+	defer r.setter.mu.Unlock()
+	r.setter.stopScheduleTimer()
+	return nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Log severity
+// must be set on every replica.
+func (r *logSettingRunnable) NeedLeaderElection() bool {
+	return false
+}
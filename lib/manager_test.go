@@ -0,0 +1,215 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Manager", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	newManager := func(informers *informertest.FakeInformers, config *rest.Config, probeAddress string,
+	) manager.Manager {
+
+		s := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(s)).To(Succeed())
+		informers.Scheme = s
+
+		mgr, err := manager.New(config, manager.Options{
+			Scheme:                 s,
+			MetricsBindAddress:     "0",
+			HealthProbeBindAddress: probeAddress,
+			NewCache: func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+				return informers, nil
+			},
+			MapperProvider: func(_ *rest.Config, _ *http.Client) (meta.RESTMapper, error) {
+				mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{v1alpha1.GroupVersion})
+				mapper.Add(v1alpha1.GroupVersion.WithKind("LogSetting"), meta.RESTScopeRoot)
+				return mapper, nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return mgr
+	}
+
+	freeAddress := func() string {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).ToNot(HaveOccurred())
+		defer l.Close()
+		return l.Addr().String()
+	}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("sets log severity from LogSetting in the manager cache", func() {
+		informers := &informertest.FakeInformers{}
+		mgr := newManager(informers, cfg, "0")
+
+		setter, err := lib.SetupWithManager(mgr, component)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(setter).To(Equal(instance))
+
+		informer, err := informers.FakeInformerFor(&v1alpha1.LogSetting{})
+		Expect(err).ToNot(HaveOccurred())
+
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelDebug},
+				},
+			},
+		}
+		informer.Add(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		updated := conf.DeepCopy()
+		updated.Spec.Configuration[0].LogLevel = v1alpha1.LogLevelVerbose
+		informer.Update(conf, updated)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+
+		informer.Delete(updated)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+
+	It("is ready only once LogSetting cache is synced", func() {
+		for _, synced := range []bool{false, true} {
+			informers := &informertest.FakeInformers{}
+			address := freeAddress()
+			mgr := newManager(informers, cfg, address)
+
+			_, err := lib.SetupWithManager(mgr, component)
+			Expect(err).ToNot(HaveOccurred())
+
+			informer, err := informers.FakeInformerFor(&v1alpha1.LogSetting{})
+			Expect(err).ToNot(HaveOccurred())
+			informer.Synced = synced
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			done := make(chan error)
+			go func() {
+				done <- mgr.Start(ctx)
+			}()
+
+			expected := http.StatusInternalServerError
+			if synced {
+				expected = http.StatusOK
+			}
+			for _, path := range []string{"/healthz/", "/readyz/"} {
+				url := fmt.Sprintf("http://%s%s%s", address, path, lib.HealthCheckName)
+				Eventually(func() int {
+					resp, err := http.Get(url)
+					if err != nil {
+						return 0
+					}
+					defer resp.Body.Close()
+					return resp.StatusCode
+				}).Should(Equal(expected))
+			}
+
+			cancel()
+			Eventually(done).Should(Receive(BeNil()))
+		}
+	})
+
+	It("sets log severity from LogSetting fetched at registration", func() {
+		conf := &v1alpha1.LogSetting{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.GroupVersion.String(),
+				Kind:       "LogSetting",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelVerbose},
+				},
+			},
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/apis/open.projectsveltos.io/v1alpha1/logsettings/default" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			Expect(json.NewEncoder(w).Encode(conf)).To(Succeed())
+		}))
+		defer server.Close()
+
+		mgr := newManager(&informertest.FakeInformers{}, &rest.Config{Host: server.URL}, "0")
+		_, err := lib.SetupWithManager(mgr, component, lib.WithInitialFetch(time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+
+	It("keeps default log severity when LogSetting cannot be fetched", func() {
+		notFound := httptest.NewServer(http.NotFoundHandler())
+		defer notFound.Close()
+
+		mgr := newManager(&informertest.FakeInformers{}, &rest.Config{Host: notFound.URL}, "0")
+		_, err := lib.SetupWithManager(mgr, component, lib.WithInitialFetch(time.Minute))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		unresponsive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer unresponsive.Close()
+
+		mgr = newManager(&informertest.FakeInformers{}, &rest.Config{Host: unresponsive.URL}, "0")
+		start := time.Now()
+		_, err = lib.SetupWithManager(mgr, component, lib.WithInitialFetch(100*time.Millisecond))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", 5*time.Second))
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+})
@@ -0,0 +1,95 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// ResolveVerbosity returns the klog verbosity level is mapped to by LogSetting
+// spec for the component configured by configuration (which can be nil).
+// Component mapping takes precedence over cluster-wide one. It returns false
+// if neither maps level, in which case the value set by the component applies.
+// Custom levels always resolve to the verbosity they are declared with.
+func ResolveVerbosity(spec *v1alpha1.LogSettingSpec, configuration *v1alpha1.ComponentConfiguration,
+	level v1alpha1.LogLevel) (int32, bool) {
+
+	if spec != nil {
+		if customLevel := findCustomLevel(spec, level); customLevel != nil {
+			return customLevel.Verbosity, true
+		}
+	}
+	if configuration != nil {
+		if v := mappedVerbosity(configuration.Mapping, level); v != nil {
+			return *v, true
+		}
+	}
+	if spec != nil {
+		if v := mappedVerbosity(spec.Mapping, level); v != nil {
+			return *v, true
+		}
+	}
+	return 0, false
+}
+
+// mappedVerbosity returns the verbosity mapping sets for level, if any
+func mappedVerbosity(mapping *v1alpha1.VerbosityMapping, level v1alpha1.LogLevel) *int32 {
+	if mapping == nil {
+		return nil
+	}
+
+	switch level {
+	case v1alpha1.LogLevelVerbose:
+		return mapping.Verbose
+	case v1alpha1.LogLevelDebug:
+		return mapping.Debug
+	case v1alpha1.LogLevelInfo, v1alpha1.LogLevelWarning, v1alpha1.LogLevelError:
+		return mapping.Info
+	}
+	return nil
+}
+
+// EntryLogLevel returns the log level of LogSetting entry c. An entry not
+// setting one, for instance to only set format, logs at info.
+func EntryLogLevel(c *v1alpha1.ComponentConfiguration) v1alpha1.LogLevel {
+	if c.LogLevel == "" {
+		return v1alpha1.LogLevelInfo
+	}
+	return c.LogLevel
+}
+
+// IsValidLogLevel returns true if level sets a log severity: it is either a
+// built-in level, other than LogLevelNotSet, or a custom level declared in
+// spec (which can be nil). LogSetting entries with any other level are skipped.
+func IsValidLogLevel(spec *v1alpha1.LogSettingSpec, level v1alpha1.LogLevel) bool {
+	switch level {
+	case v1alpha1.LogLevelError, v1alpha1.LogLevelWarning, v1alpha1.LogLevelInfo,
+		v1alpha1.LogLevelDebug, v1alpha1.LogLevelVerbose:
+		return true
+	}
+	return spec != nil && findCustomLevel(spec, level) != nil
+}
+
+// findCustomLevel returns the custom level named level declared in spec, if any
+func findCustomLevel(spec *v1alpha1.LogSettingSpec, level v1alpha1.LogLevel) *v1alpha1.CustomLevel {
+	for i := range spec.CustomLevels {
+		if spec.CustomLevels[i].Name == string(level) {
+			return &spec.CustomLevels[i]
+		}
+	}
+	return nil
+}
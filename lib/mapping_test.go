@@ -0,0 +1,104 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"flag"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Mapping", func() {
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("honors component and cluster-wide mappings over compiled-in values", func() {
+		instance.SetDebugValue(lib.LogDebug)
+
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Mapping: &v1alpha1.VerbosityMapping{Debug: pointer.Int32(7), Verbose: pointer.Int32(9)},
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelDebug,
+						Mapping:   &v1alpha1.VerbosityMapping{Debug: pointer.Int32(8)},
+					},
+				},
+			},
+		}
+
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal("8"))
+
+		// Component mapping does not set verbose. Cluster-wide mapping applies
+		conf.Spec.Configuration[0].LogLevel = v1alpha1.LogLevelVerbose
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal("9"))
+
+		conf.Spec.Configuration[0].LogLevel = v1alpha1.LogLevelDebug
+		conf.Spec.Configuration[0].Mapping = nil
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal("7"))
+
+		conf.Spec.Mapping = nil
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+	})
+
+	It("sets verbosity of custom log levels", func() {
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				CustomLevels: []v1alpha1.CustomLevel{
+					{Name: "trace", Verbosity: 12},
+				},
+				// Mappings do not affect custom levels
+				Mapping: &v1alpha1.VerbosityMapping{Verbose: pointer.Int32(9)},
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevel("trace")},
+				},
+			},
+		})
+
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal("12"))
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+// setNode sets the node the component runs on and applies current
+// configuration again
+func (l *LogSetter) setNode(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.nodeName = name
+	l.reapply()
+}
+
+// setNodeLabels sets labels of the node the component runs on. Current
+// configuration is applied again if they changed.
+func (l *LogSetter) setNodeLabels(nodeLabels map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if reflect.DeepEqual(labels.Set(nodeLabels), l.nodeLabels) {
+		return
+	}
+	l.nodeLabels = make(labels.Set, len(nodeLabels))
+	for k, v := range nodeLabels {
+		l.nodeLabels[k] = v
+	}
+	l.reapply()
+}
+
+// watchNode sets node labels from Node name till ctx is done
+func (l *LogSetter) watchNode(ctx context.Context, clientset kubernetes.Interface, name string) {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+	informer := factory.Core().V1().Nodes().Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if node, ok := obj.(*corev1.Node); ok {
+				l.setNodeLabels(node.Labels)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if node, ok := newObj.(*corev1.Node); ok {
+				l.setNodeLabels(node.Labels)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			l.setNodeLabels(nil)
+		},
+	})
+	if err != nil {
+		l.logger.Error(err, "Failed to watch Node", "node", name)
+		return
+	}
+
+	factory.Start(ctx.Done())
+}
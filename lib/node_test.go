@@ -0,0 +1,110 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Node scoped entries", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		instance.ClearNode()
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("applies entries listing the node only on that node", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, Nodes: []string{"worker-3"}, LogLevel: v1alpha1.LogLevelVerbose},
+					{Component: component, LogLevel: v1alpha1.LogLevelDebug},
+				},
+			},
+		}
+
+		// Node is not known: entry limited to nodes never applies
+		lib.UpdateLogLevel(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		instance.SetNode("worker-1")
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		// Entry limited to nodes beats the other one, though it comes first
+		instance.SetNode("worker-3")
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+
+	It("applies entries with a node selector on nodes with matching labels", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component:    component,
+						NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"zone": "eu-west-1a"}},
+						LogLevel:     v1alpha1.LogLevelDebug,
+					},
+				},
+			},
+		}
+		lib.UpdateLogLevel(conf)
+
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "worker-3",
+				Labels: map[string]string{"zone": "eu-west-1a"},
+			},
+		}
+		clientset := fake.NewSimpleClientset(node)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		instance.SetNode(node.Name)
+		instance.WatchNode(ctx, clientset, node.Name)
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		node.Labels = map[string]string{"zone": "eu-west-1b"}
+		_, err := clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+})
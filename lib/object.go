@@ -0,0 +1,152 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// elevationSink is a LogSink which enables records up to level, even when the
+// wrapped sink filters them out.
+type elevationSink struct {
+	sink  logr.LogSink
+	level int
+}
+
+// LoggerFor returns the logger to use while reconciling obj. If obj matches
+// one of the Objects listed in the LogSetting entry for this component, the
+// returned logger logs records up to the log severity set for that object.
+// Loggers are based on the component logger: the manager one when registered
+// with SetupWithManager, the one passed to RegisterForLogSettings otherwise.
+func (l *LogSetter) LoggerFor(obj client.Object) logr.Logger {
+	return l.ElevatedLogger(l.componentLogger, obj)
+}
+
+// ElevatedLogger is like LoggerFor, but returns logger itself, elevated if
+// obj matches one of the Objects listed in the LogSetting entry for this
+// component. Use it to keep name and values of the caller logger, like the
+// one controller-runtime passes to Reconcile.
+func (l *LogSetter) ElevatedLogger(logger logr.Logger, obj client.Object) logr.Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	configuration := l.configuration
+	if configuration == nil {
+		return logger
+	}
+
+	gvk, err := l.objectGVK(obj)
+	if err != nil {
+		l.logger.V(LogDebug).Info("failed to get GroupVersionKind", "error", err)
+		return logger
+	}
+
+	for i := range configuration.Objects {
+		target := &configuration.Objects[i]
+		if !matchesTarget(target, gvk, obj) {
+			continue
+		}
+
+		level := target.LogLevel
+		if level == "" {
+			level = v1alpha1.LogLevelDebug
+		}
+		_, value, ok := l.logLevelValue(configuration, level)
+		if !ok {
+			return logger
+		}
+		return logr.New(&elevationSink{sink: wrapSink(logger.GetSink()), level: verbosity(value)})
+	}
+
+	return logger
+}
+
+// objectGVK returns GroupVersionKind of obj. Must be called with mu held.
+func (l *LogSetter) objectGVK(obj client.Object) (schema.GroupVersionKind, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind != "" {
+		return gvk, nil
+	}
+	return apiutil.GVKForObject(obj, l.scheme)
+}
+
+// matchesTarget returns true if obj, of kind gvk, is identified by target
+func matchesTarget(target *v1alpha1.ObjectTarget, gvk schema.GroupVersionKind, obj client.Object) bool {
+	if target.Kind != gvk.Kind || target.Group != gvk.Group {
+		return false
+	}
+	if target.Version != "" && target.Version != gvk.Version {
+		return false
+	}
+	if target.Namespace != "" && target.Namespace != obj.GetNamespace() {
+		return false
+	}
+	if target.Name != "" && target.Name != obj.GetName() {
+		return false
+	}
+	if target.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(target.Selector)
+		if err != nil || !selector.Matches(labels.Set(obj.GetLabels())) {
+			return false
+		}
+	}
+	return true
+}
+
+// Init is a no-op: wrapped sink is already initialized.
+func (s *elevationSink) Init(info logr.RuntimeInfo) {
+}
+
+func (s *elevationSink) Enabled(level int) bool {
+	return level <= s.level || s.sink.Enabled(level)
+}
+
+func (s *elevationSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if s.sink.Enabled(level) {
+		s.sink.Info(level, msg, keysAndValues...)
+		return
+	}
+	// Wrapped sink filters out this level. Record is logged at V(0), with
+	// its original level as "v".
+	kv := make([]interface{}, 0, len(keysAndValues)+2)
+	kv = append(kv, keysAndValues...)
+	kv = append(kv, "v", level)
+	s.sink.Info(0, msg, kv...)
+}
+
+func (s *elevationSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+}
+
+func (s *elevationSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &elevationSink{sink: s.sink.WithValues(keysAndValues...), level: s.level}
+}
+
+func (s *elevationSink) WithName(name string) logr.LogSink {
+	return &elevationSink{sink: s.sink.WithName(name), level: s.level}
+}
+
+func (s *elevationSink) WithCallDepth(depth int) logr.LogSink {
+	return &elevationSink{sink: withCallDepth(s.sink, depth), level: s.level}
+}
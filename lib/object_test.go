@@ -0,0 +1,130 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"flag"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr/funcr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("LoggerFor", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelInfo,
+						Objects: []v1alpha1.ObjectTarget{
+							{Group: "apps", Kind: "Deployment", Namespace: "default", Name: "nginx"},
+							{Kind: "Pod", Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}}},
+							{
+								Group: "cluster.x-k8s.io", Version: "v1beta1", Kind: "Cluster", Name: "production",
+								LogLevel: v1alpha1.LogLevelVerbose,
+							},
+						},
+					},
+				},
+			},
+		})
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+
+	It("returns elevated logger for matching objects", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+		}
+		logger := instance.LoggerFor(deployment)
+		Expect(logger.V(lib.LogDebug).Enabled()).To(BeTrue())
+		Expect(logger.V(lib.LogVerbose).Enabled()).To(BeFalse())
+		Expect(logger.WithValues("deployment", "nginx").V(lib.LogDebug).Enabled()).To(BeTrue())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx-1",
+				Labels: map[string]string{"app": "nginx"}},
+		}
+		Expect(instance.LoggerFor(pod).V(lib.LogDebug).Enabled()).To(BeTrue())
+
+		cluster := &unstructured.Unstructured{}
+		cluster.SetAPIVersion("cluster.x-k8s.io/v1beta1")
+		cluster.SetKind("Cluster")
+		cluster.SetNamespace("default")
+		cluster.SetName("production")
+		Expect(instance.LoggerFor(cluster).V(lib.LogVerbose).Enabled()).To(BeTrue())
+	})
+
+	It("returns component logger for other objects", func() {
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "apache"},
+		}
+		Expect(instance.LoggerFor(deployment).V(lib.LogDebug).Enabled()).To(BeFalse())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "apache-1",
+				Labels: map[string]string{"app": "apache"}},
+		}
+		Expect(instance.LoggerFor(pod).V(lib.LogDebug).Enabled()).To(BeFalse())
+
+		cluster := &unstructured.Unstructured{}
+		cluster.SetAPIVersion("cluster.x-k8s.io/v1alpha4")
+		cluster.SetKind("Cluster")
+		cluster.SetNamespace("default")
+		cluster.SetName("production")
+		Expect(instance.LoggerFor(cluster).V(lib.LogDebug).Enabled()).To(BeFalse())
+	})
+
+	It("elevates the caller logger", func() {
+		var records []string
+		logger := funcr.New(func(prefix, args string) {
+			records = append(records, prefix+" "+args)
+		}, funcr.Options{}).WithName("reconciler")
+
+		deployment := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+		}
+		instance.ElevatedLogger(logger, deployment).V(lib.LogDebug).Info("elevated record")
+		Expect(records).To(HaveLen(1))
+		Expect(records[0]).To(HavePrefix("reconciler "))
+		Expect(records[0]).To(ContainSubstring("elevated record"))
+
+		deployment.Name = "apache"
+		Expect(instance.ElevatedLogger(logger, deployment)).To(Equal(logger))
+	})
+})
@@ -0,0 +1,152 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Option configures how a LogSetter is registered
+type Option func(*options)
+
+type options struct {
+	// logger is used by LogSetter. When not set, the caller default is used.
+	logger *logr.Logger
+
+	// klogLogger, if set, receives klog records in text format
+	klogLogger *logr.Logger
+
+	// initialFetchTimeout, when not zero, bounds the synchronous fetch of
+	// LogSetting done before registration returns
+	initialFetchTimeout time.Duration
+
+	// persistencePath, when set, is where last LogSetting processed is persisted
+	persistencePath string
+
+	// signalHandlers is true if log severity can be changed with signals
+	signalHandlers bool
+
+	// podNamespace and podName identify the Pod whose annotations are watched
+	podNamespace string
+	podName      string
+
+	// annotationsFile is the downward API annotations file, if any
+	annotationsFile string
+
+	// tags the component is registered with
+	tags map[string]string
+
+	// nodeName is the node the component runs on, if known
+	nodeName string
+}
+
+// WithLogger sets the logger used by LogSetter
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
+
+// WithKlogLogger makes klog write records in text format to logger. On
+// registration, LogSetter sets the klog logger, so that log format can be
+// changed at run-time: use this option instead of klog.SetLogger. Logger must
+// not write to klog itself. When not set, klog text output is kept.
+func WithKlogLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.klogLogger = &logger
+	}
+}
+
+// WithInitialFetch makes registration fetch LogSetting and set log severity
+// before returning, so that startup logs already honor the configured level.
+// If LogSetting cannot be fetched within timeout, registration returns anyway
+// and default log severity is kept till LogSetting is received by the informer.
+func WithInitialFetch(timeout time.Duration) Option {
+	return func(o *options) {
+		o.initialFetchTimeout = timeout
+	}
+}
+
+// WithPersistence makes LogSetter persist, at path, the last LogSetting
+// processed. On registration, persisted LogSetting is applied at once, so
+// that log severity survives restarts even when API server is unreachable.
+// Once the informer syncs, live LogSetting takes over.
+// Path should be on a volume surviving container restarts, like an emptyDir.
+func WithPersistence(path string) Option {
+	return func(o *options) {
+		o.persistencePath = path
+	}
+}
+
+// WithSignalHandlers makes LogSetter handle SIGUSR1 and SIGUSR2, to change log
+// severity without cluster credentials. SIGUSR1 steps log severity up through
+// info, debug and verbose. SIGUSR2 resets it to the one set by LogSetting.
+// Signals are not handled on Windows.
+func WithSignalHandlers() Option {
+	return func(o *options) {
+		o.signalHandlers = true
+	}
+}
+
+// WithPod makes LogSetter watch Pod namespace/name, normally its own Pod. While
+// the Pod has the v1alpha1.PodLogLevelAnnotation annotation, the log level it
+// contains overrides the one set by LogSetting.
+// Pod name is also used, instead of host name, to decide whether the component
+// is selected by LogSetting entries limited to a percentage of replicas.
+func WithPod(namespace, name string) Option {
+	return func(o *options) {
+		o.podNamespace = namespace
+		o.podName = name
+	}
+}
+
+// WithAnnotationsFile is like WithPod, but Pod annotations are read from the
+// file at path, mounted with the downward API. No permission on Pods is needed.
+func WithAnnotationsFile(path string) Option {
+	return func(o *options) {
+		o.annotationsFile = path
+	}
+}
+
+// WithTags registers the component with tags, like team=payments or
+// tier=controller. LogSetting entries with a TagSelector matching them apply
+// to the component, unless an entry for the exact component is present.
+func WithTags(tags map[string]string) Option {
+	return func(o *options) {
+		o.tags = tags
+	}
+}
+
+// WithNode sets the node the component runs on, normally read from spec.nodeName
+// with the downward API. LogSetting entries limited to nodes, with Nodes or
+// NodeSelector, apply only when it matches. Node labels are watched as well, so
+// ServiceAccount needs get/list/watch permission on Nodes for NodeSelector to match.
+func WithNode(name string) Option {
+	return func(o *options) {
+		o.nodeName = name
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
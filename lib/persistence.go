@@ -0,0 +1,100 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// restorePersisted reads the LogSetting persisted at path, if any, and sets
+// log severity accordingly. From now on every LogSetting processed is
+// persisted at path.
+func (l *LogSetter) restorePersisted(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.persistencePath = path
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.logger.Error(err, "Failed to read persisted LogSetting", "path", path)
+		}
+		return
+	}
+	d := &v1alpha1.LogSetting{}
+	if err := json.Unmarshal(content, d); err != nil {
+		l.logger.Error(err, "Failed to parse persisted LogSetting", "path", path)
+		return
+	}
+
+	l.logger.Info("Restoring persisted LogSetting", "path", path)
+	l.restored = true
+	l.updateLogLevel(d)
+}
+
+// persist writes d at persistence path, if one is set. File is replaced
+// atomically, so a crash never leaves a partial file. Must be called with mu held.
+func (l *LogSetter) persist(d *v1alpha1.LogSetting) {
+	if l.persistencePath == "" {
+		return
+	}
+
+	content, err := json.Marshal(d)
+	if err != nil {
+		l.logger.Error(err, "Failed to marshal LogSetting")
+		return
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.persistencePath), filepath.Base(l.persistencePath)+".*")
+	if err != nil {
+		l.logger.Error(err, "Failed to persist LogSetting", "path", l.persistencePath)
+		return
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), l.persistencePath)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		l.logger.Error(err, "Failed to persist LogSetting", "path", l.persistencePath)
+	}
+}
+
+// logSettingSynced is called once the LogSetting informer is synced. found
+// is false if no LogSetting exists: in that case any persisted configuration
+// restored at startup is stale and default log severity is set.
+func (l *LogSetter) logSettingSynced(found bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.restored {
+		return
+	}
+	l.restored = false
+	if !found {
+		l.logger.Info("LogSetting not found. Dropping persisted LogSetting")
+		l.updateLogLevel(&v1alpha1.LogSetting{})
+	}
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Persistence", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	newLogSetting := func(level v1alpha1.LogLevel) *v1alpha1.LogSetting {
+		return &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: level},
+				},
+			},
+		}
+	}
+
+	readPersisted := func(path string) *v1alpha1.LogSetting {
+		content, err := os.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		d := &v1alpha1.LogSetting{}
+		Expect(json.Unmarshal(content, d)).To(Succeed())
+		return d
+	}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+	})
+
+	AfterEach(func() {
+		instance.SetPersistencePath("")
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("restores persisted LogSetting till live one is synced", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "logsetting.json")
+		content, err := json.Marshal(newLogSetting(v1alpha1.LogLevelVerbose))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(os.WriteFile(path, content, 0600)).To(Succeed())
+
+		s := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(s)).To(Succeed())
+		mgr, err := manager.New(cfg, manager.Options{
+			Scheme:             s,
+			MetricsBindAddress: "0",
+			NewCache: func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+				return &informertest.FakeInformers{Scheme: s}, nil
+			},
+			MapperProvider: func(_ *rest.Config, _ *http.Client) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = lib.SetupWithManager(mgr, component, lib.WithPersistence(path))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+
+		lib.UpdateLogLevel(newLogSetting(v1alpha1.LogLevelDebug))
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogDebug)))
+		Expect(readPersisted(path).Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelDebug))
+
+		// Manager cache contains no LogSetting: persisted one is dropped
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan error)
+		go func() {
+			done <- mgr.Start(ctx)
+		}()
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogInfo)))
+		Expect(readPersisted(path).Spec.Configuration).To(BeEmpty())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("persists every LogSetting processed", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "logsetting.json")
+		instance.SetPersistencePath(path)
+		lib.UpdateLogLevel(newLogSetting(v1alpha1.LogLevelVerbose))
+		Expect(readPersisted(path).Spec.Configuration[0].LogLevel).To(Equal(v1alpha1.LogLevelVerbose))
+
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+		Expect(readPersisted(path).Spec.Configuration).To(BeEmpty())
+	})
+})
@@ -0,0 +1,170 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// annotationsFilePollInterval is how often the downward API annotations file
+// is read
+const annotationsFilePollInterval = 10 * time.Second
+
+// shortLevelNames maps the short names accepted in Pod annotation, same as
+// those accepted by helper CLI, to built-in log levels
+var shortLevelNames = map[string]v1alpha1.LogLevel{
+	"error":   v1alpha1.LogLevelError,
+	"warning": v1alpha1.LogLevelWarning,
+	"info":    v1alpha1.LogLevelInfo,
+	"debug":   v1alpha1.LogLevelDebug,
+	"verbose": v1alpha1.LogLevelVerbose,
+}
+
+// setPodLevel sets the log level set by Pod annotation. LogLevelNotSet means
+// no annotation is present. A level which is neither built-in nor declared in
+// LogSetting is ignored, till it gets declared.
+func (l *LogSetter) setPodLevel(level v1alpha1.LogLevel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level == l.podLevel {
+		return
+	}
+
+	if level == v1alpha1.LogLevelNotSet {
+		l.logger.Info("Pod annotation removed. Using LogSetting log level")
+	} else if _, _, ok := l.logLevelValue(l.configuration, level); !ok {
+		l.logger.Error(fmt.Errorf("unknown log level %q", level),
+			"Pod annotation does not set a valid log level. Ignoring it")
+	} else {
+		l.logger.Info("Pod annotation overrides LogSetting log level", "logLevel", level)
+	}
+	l.podLevel = level
+
+	d := l.logSetting
+	if d == nil {
+		d = &v1alpha1.LogSetting{}
+	}
+	l.updateLogLevel(d)
+}
+
+// podLevelFromAnnotations returns the log level set by annotations. Value is
+// either a log level as used in LogSetting or a short name, like debug.
+func podLevelFromAnnotations(annotations map[string]string) v1alpha1.LogLevel {
+	level, ok := annotations[v1alpha1.PodLogLevelAnnotation]
+	if !ok || level == "" {
+		return v1alpha1.LogLevelNotSet
+	}
+	if builtin, ok := shortLevelNames[level]; ok {
+		return builtin
+	}
+	return v1alpha1.LogLevel(level)
+}
+
+// watchPod sets log level from annotations of Pod namespace/name till ctx is
+// done
+func (l *LogSetter) watchPod(ctx context.Context, clientset kubernetes.Interface, namespace, name string) {
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		}),
+	)
+	informer := factory.Core().V1().Pods().Informer()
+
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				l.setPodLevel(podLevelFromAnnotations(pod.Annotations))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if pod, ok := newObj.(*corev1.Pod); ok {
+				l.setPodLevel(podLevelFromAnnotations(pod.Annotations))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			l.setPodLevel(v1alpha1.LogLevelNotSet)
+		},
+	})
+	if err != nil {
+		l.logger.Error(err, "Failed to watch Pod", "pod", namespace+"/"+name)
+		return
+	}
+
+	factory.Start(ctx.Done())
+}
+
+// readAnnotationsFile sets log level from the downward API annotations file
+// at path. Missing file means no annotation is present.
+func (l *LogSetter) readAnnotationsFile(path string) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			l.logger.Error(err, "Failed to read annotations file", "path", path)
+			return
+		}
+	}
+	l.setPodLevel(podLevelFromAnnotations(parseAnnotations(string(content))))
+}
+
+// pollAnnotationsFile reads the annotations file at path every interval, till
+// ctx is done
+func (l *LogSetter) pollAnnotationsFile(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.readAnnotationsFile(path)
+		}
+	}
+}
+
+// parseAnnotations parses a downward API annotations file, where each line
+// is key="value" with value quoted as a Go string
+func parseAnnotations(content string) map[string]string {
+	annotations := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		annotations[key] = value
+	}
+	return annotations
+}
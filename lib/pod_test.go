@@ -0,0 +1,153 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Pod annotation", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	conf := &v1alpha1.LogSetting{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "default",
+		},
+		Spec: v1alpha1.LogSettingSpec{
+			Configuration: []v1alpha1.ComponentConfiguration{
+				{Component: component, LogLevel: v1alpha1.LogLevelVerbose},
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+	})
+
+	AfterEach(func() {
+		instance.ClearPodLevel()
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("overrides LogSetting while Pod annotation is present", func() {
+		lib.UpdateLogLevel(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default",
+				Name:      "nginx",
+				Annotations: map[string]string{
+					v1alpha1.PodLogLevelAnnotation: string(v1alpha1.LogLevelDebug),
+				},
+			},
+		}
+		clientset := fake.NewSimpleClientset(pod)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		instance.WatchPod(ctx, clientset, pod.Namespace, pod.Name)
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		pod.Annotations = nil
+		_, err := clientset.CoreV1().Pods(pod.Namespace).Update(ctx, pod, metav1.UpdateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+
+	It("reads Pod annotations from downward API file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "annotations")
+		writeAnnotations := func(level v1alpha1.LogLevel) {
+			content := fmt.Sprintf("kubernetes.io/config.seen=%q\n%s=%q\n",
+				"2023-04-04T13:41:23Z", v1alpha1.PodLogLevelAnnotation, level)
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		}
+		writeAnnotations(v1alpha1.LogLevelInfo)
+
+		s := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(s)).To(Succeed())
+		mgr, err := manager.New(cfg, manager.Options{
+			Scheme:             s,
+			MetricsBindAddress: "0",
+			NewCache: func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+				return &informertest.FakeInformers{Scheme: s}, nil
+			},
+			MapperProvider: func(_ *rest.Config, _ *http.Client) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		lib.UpdateLogLevel(conf)
+		_, err = lib.SetupWithManager(mgr, component, lib.WithAnnotationsFile(path))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go instance.PollAnnotationsFile(ctx, path, 10*time.Millisecond)
+
+		writeAnnotations(v1alpha1.LogLevelDebug)
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		Expect(os.Remove(path)).To(Succeed())
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+
+	It("accepts short level names and ignores unknown levels", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "annotations")
+		writeAnnotations := func(level string) {
+			content := fmt.Sprintf("%s=%q\n", v1alpha1.PodLogLevelAnnotation, level)
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		}
+		lib.UpdateLogLevel(conf)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		defer cancel()
+		go instance.PollAnnotationsFile(ctx, path, 10*time.Millisecond)
+
+		writeAnnotations("debug")
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		writeAnnotations("chatty")
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+})
@@ -0,0 +1,153 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Quiet log levels", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	update := func(c v1alpha1.ComponentConfiguration) {
+		c.Component = component
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{c},
+			},
+		})
+	}
+
+	updateLevel := func(level v1alpha1.LogLevel) {
+		update(v1alpha1.ComponentConfiguration{LogLevel: level})
+	}
+
+	fileContent := func(file string) string {
+		klog.Flush()
+		data, err := os.ReadFile(file)
+		Expect(err).To(BeNil())
+		return string(data)
+	}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+		lib.SetJSONOutput(os.Stderr)
+	})
+
+	It("only lets warnings and errors through to stderr", func() {
+		instance.SetInfoValue(lib.LogInfo)
+		originalToStderr := flag.Lookup("logtostderr").Value.String()
+		originalThreshold := flag.Lookup("stderrthreshold").Value.String()
+
+		updateLevel(v1alpha1.LogLevelWarning)
+		Expect(flag.Lookup("v").Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+		Expect(flag.Lookup("logtostderr").Value.String()).To(Equal("false"))
+		Expect(flag.Lookup("alsologtostderr").Value.String()).To(Equal("false"))
+		Expect(flag.Lookup("stderrthreshold").Value.String()).To(Equal("1"))
+
+		updateLevel(v1alpha1.LogLevelError)
+		Expect(flag.Lookup("stderrthreshold").Value.String()).To(Equal("2"))
+
+		updateLevel(v1alpha1.LogLevelInfo)
+		Expect(flag.Lookup("logtostderr").Value.String()).To(Equal(originalToStderr))
+		Expect(flag.Lookup("stderrthreshold").Value.String()).To(Equal(originalThreshold))
+	})
+
+	It("drops info records from log file", func() {
+		logFile := filepath.Join(GinkgoT().TempDir(), "component.log")
+
+		update(v1alpha1.ComponentConfiguration{LogLevel: v1alpha1.LogLevelWarning,
+			KlogFlags: &v1alpha1.KlogFlags{LogFile: pointer.String(logFile)}})
+		klog.Info("quiet info record")
+		klog.Warning("quiet warning record")
+		Expect(fileContent(logFile)).ToNot(ContainSubstring("quiet info record"))
+		Expect(fileContent(logFile)).To(ContainSubstring("quiet warning record"))
+
+		update(v1alpha1.ComponentConfiguration{LogLevel: v1alpha1.LogLevelInfo,
+			KlogFlags: &v1alpha1.KlogFlags{LogFile: pointer.String(logFile)}})
+		klog.Info("info record")
+		Expect(fileContent(logFile)).To(ContainSubstring("info record"))
+	})
+
+	It("does not prevent log file from being set later", func() {
+		logFile := filepath.Join(GinkgoT().TempDir(), "component.log")
+
+		updateLevel(v1alpha1.LogLevelError)
+		update(v1alpha1.ComponentConfiguration{LogLevel: v1alpha1.LogLevelInfo,
+			KlogFlags: &v1alpha1.KlogFlags{LogFile: pointer.String(logFile)}})
+		klog.Info("record after quiet level")
+		Expect(fileContent(logFile)).To(ContainSubstring("record after quiet level"))
+	})
+
+	It("drops info records in JSON format", func() {
+		var buf syncBuffer
+		lib.SetJSONOutput(&buf)
+
+		update(v1alpha1.ComponentConfiguration{LogLevel: v1alpha1.LogLevelError, Format: v1alpha1.LogFormatJSON})
+		klog.InfoS("quiet info record")
+		klog.ErrorS(nil, "quiet error record")
+		Expect(buf.String()).ToNot(ContainSubstring("quiet info record"))
+		Expect(buf.String()).To(ContainSubstring("quiet error record"))
+
+		update(v1alpha1.ComponentConfiguration{LogLevel: v1alpha1.LogLevelInfo, Format: v1alpha1.LogFormatJSON})
+		klog.InfoS("info record")
+		Expect(buf.String()).To(ContainSubstring("info record"))
+	})
+})
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// logRecord is a verbose log record kept by a flightRecorder
+type logRecord struct {
+	time          time.Time
+	level         int
+	name          string
+	msg           string
+	keysAndValues []interface{}
+}
+
+// flightRecorder keeps the most recent verbose log records in a bounded
+// ring buffer.
+type flightRecorder struct {
+	mu      sync.Mutex
+	records []logRecord
+	next    int
+	full    bool
+	out     io.Writer
+
+	// closed is true once the recorder is not used anymore
+	closed bool
+}
+
+func newFlightRecorder(size int) *flightRecorder {
+	if size <= 0 {
+		size = 1
+	}
+	return &flightRecorder{
+		records: make([]logRecord, size),
+		out:     os.Stderr,
+	}
+}
+
+func (r *flightRecorder) add(record logRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	r.records[r.next] = record
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// dump writes all buffered records, oldest first, and empties the buffer.
+func (r *flightRecorder) dump(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return
+	}
+	var records []logRecord
+	if r.full {
+		records = append(records, r.records[r.next:]...)
+	}
+	records = append(records, r.records[:r.next]...)
+
+	fmt.Fprintf(r.out, "--- flight recorder dump (%s): %d records ---\n", reason, len(records))
+	for i := range records {
+		fmt.Fprintln(r.out, formatRecord(&records[i]))
+	}
+	fmt.Fprintf(r.out, "--- end of flight recorder dump ---\n")
+
+	for i := range r.records {
+		r.records[i] = logRecord{}
+	}
+	r.next = 0
+	r.full = false
+}
+
+func formatRecord(record *logRecord) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s V(%d) ", record.time.Format(time.RFC3339Nano), record.level)
+	if record.name != "" {
+		fmt.Fprintf(&b, "%s: ", record.name)
+	}
+	fmt.Fprintf(&b, "%q", record.msg)
+	for i := 0; i+1 < len(record.keysAndValues); i += 2 {
+		fmt.Fprintf(&b, " %v=%q", record.keysAndValues[i], fmt.Sprintf("%+v", record.keysAndValues[i+1]))
+	}
+	return b.String()
+}
+
+// recorderRef refers to a flightRecorder from all loggers sharing it. Once
+// it is not reachable anymore, the recorder is released.
+type recorderRef struct {
+	recorder *flightRecorder
+}
+
+// recorderSink is a LogSink which captures verbose records into a
+// flightRecorder, even when those are filtered out by the wrapped sink.
+type recorderSink struct {
+	sink          logr.LogSink
+	ref           *recorderRef
+	setter        *LogSetter
+	name          string
+	keysAndValues []interface{}
+}
+
+// NewFlightRecorderLogger returns a logger which keeps, in a ring buffer of
+// the given size, the most recent verbose records (V(1) up to the verbose
+// level, or the most verbose custom level) logged through it, even when those
+// are not enabled.
+// Buffer is dumped to stderr every time an error is logged through this logger
+// and when dumpTrigger field of the LogSetting entry for this component changes.
+// Buffer is released with CloseFlightRecorder, or once the logger, and loggers
+// derived from it, are garbage collected.
+func (l *LogSetter) NewFlightRecorderLogger(logger logr.Logger, size int) logr.Logger {
+	recorder := newFlightRecorder(size)
+
+	l.mu.Lock()
+	l.recorders = append(l.recorders, recorder)
+	l.mu.Unlock()
+
+	ref := &recorderRef{recorder: recorder}
+	runtime.SetFinalizer(ref, func(ref *recorderRef) {
+		l.releaseRecorder(ref.recorder)
+	})
+	return logr.New(&recorderSink{sink: wrapSink(logger.GetSink()), ref: ref, setter: l})
+}
+
+// CloseFlightRecorder stops logger, returned by NewFlightRecorderLogger or
+// derived from it, from keeping records and releases its buffer. Records
+// still buffered are not dumped.
+func (l *LogSetter) CloseFlightRecorder(logger logr.Logger) {
+	if s, ok := logger.GetSink().(*recorderSink); ok {
+		l.releaseRecorder(s.ref.recorder)
+	}
+}
+
+// releaseRecorder closes recorder and forgets it
+func (l *LogSetter) releaseRecorder(recorder *flightRecorder) {
+	recorder.mu.Lock()
+	recorder.closed = true
+	recorder.records = nil
+	recorder.mu.Unlock()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.recorders {
+		if l.recorders[i] == recorder {
+			l.recorders = append(l.recorders[:i], l.recorders[i+1:]...)
+			break
+		}
+	}
+}
+
+// recordedValue returns the highest klog verbosity flight recorders keep:
+// the one LogLevelVerbose resolves to, or the one of a more verbose custom
+// level. Must be called with mu held.
+func (l *LogSetter) recordedValue() int {
+	_, value, _ := l.logLevelValue(l.configuration, v1alpha1.LogLevelVerbose)
+	recorded := verbosity(value)
+	if l.logSetting != nil {
+		for i := range l.logSetting.Spec.CustomLevels {
+			if v := int(l.logSetting.Spec.CustomLevels[i].Verbosity); v > recorded {
+				recorded = v
+			}
+		}
+	}
+	return recorded
+}
+
+// captured returns true if records at level are kept by the flight recorder
+func (s *recorderSink) captured(level int) bool {
+	return level > 0 && level <= s.setter.published().recordedValue
+}
+
+// Init is a no-op: wrapped sink is already initialized.
+func (s *recorderSink) Init(info logr.RuntimeInfo) {
+}
+
+func (s *recorderSink) Enabled(level int) bool {
+	return s.captured(level) || s.sink.Enabled(level)
+}
+
+func (s *recorderSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if s.captured(level) {
+		kv := make([]interface{}, 0, len(s.keysAndValues)+len(keysAndValues))
+		kv = append(kv, s.keysAndValues...)
+		kv = append(kv, keysAndValues...)
+		s.ref.recorder.add(logRecord{time: time.Now(), level: level, name: s.name, msg: msg, keysAndValues: kv})
+	}
+
+	if s.sink.Enabled(level) {
+		s.sink.Info(level, msg, keysAndValues...)
+	}
+}
+
+func (s *recorderSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+	s.ref.recorder.dump("error logged")
+}
+
+func (s *recorderSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	kv := make([]interface{}, 0, len(s.keysAndValues)+len(keysAndValues))
+	kv = append(kv, s.keysAndValues...)
+	kv = append(kv, keysAndValues...)
+	return &recorderSink{sink: s.sink.WithValues(keysAndValues...), ref: s.ref, setter: s.setter,
+		name: s.name, keysAndValues: kv}
+}
+
+func (s *recorderSink) WithName(name string) logr.LogSink {
+	fullName := name
+	if s.name != "" {
+		fullName = s.name + "/" + name
+	}
+	return &recorderSink{sink: s.sink.WithName(name), ref: s.ref, setter: s.setter,
+		name: fullName, keysAndValues: s.keysAndValues}
+}
+
+func (s *recorderSink) WithCallDepth(depth int) logr.LogSink {
+	return &recorderSink{sink: withCallDepth(s.sink, depth), ref: s.ref, setter: s.setter,
+		name: s.name, keysAndValues: s.keysAndValues}
+}
+
+// checkDumpTrigger dumps all flight recorders if dumpTrigger of the
+// configuration for this component has changed. Must be called with mu held.
+func (l *LogSetter) checkDumpTrigger(dumpTrigger string) {
+	previous := l.dumpTrigger
+	l.dumpTrigger = &dumpTrigger
+	if previous == nil || *previous == dumpTrigger {
+		return
+	}
+
+	l.logger.Info("Dump trigger changed. Dumping flight recorders", "dumpTrigger", dumpTrigger)
+	for _, recorder := range l.recorders {
+		recorder.dump("dump trigger changed")
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2/klogr"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("FlightRecorder", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("dumps suppressed verbose logs when an error is logged", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelInfo},
+				},
+			},
+		}
+
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(conf)
+
+		logger := instance.NewFlightRecorderLogger(klogr.New(), 3)
+		var buf bytes.Buffer
+		instance.SetFlightRecorderOutput(&buf)
+
+		logger.V(lib.LogDebug).Info("first")
+		logger.V(lib.LogVerbose).Info("second")
+		logger.WithName("reconciler").V(lib.LogDebug).Info("third", "cluster", "production")
+		logger.WithValues("attempt", 4).V(lib.LogVerbose).Info("fourth")
+		// Level above verbose is not recorded
+		logger.V(lib.LogVerbose + 1).Info("ignored")
+		Expect(buf.Len()).To(BeZero())
+
+		logger.Error(errors.New("failure"), "reconciliation failed")
+		output := buf.String()
+		Expect(output).ToNot(ContainSubstring("first"))
+		Expect(output).To(ContainSubstring("second"))
+		Expect(output).To(ContainSubstring(`reconciler: "third" cluster="production"`))
+		Expect(output).To(ContainSubstring(`"fourth" attempt="4"`))
+		Expect(output).ToNot(ContainSubstring("ignored"))
+
+		// Buffer is emptied after a dump
+		buf.Reset()
+		logger.Error(errors.New("failure"), "reconciliation failed")
+		Expect(buf.String()).ToNot(ContainSubstring("second"))
+	})
+
+	It("dumps suppressed verbose logs when dump trigger changes", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelInfo, DumpTrigger: "first"},
+				},
+			},
+		}
+
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(conf)
+
+		logger := instance.NewFlightRecorderLogger(klogr.New(), 10)
+		var buf bytes.Buffer
+		instance.SetFlightRecorderOutput(&buf)
+
+		logger.V(lib.LogDebug).Info("before trigger")
+		lib.UpdateLogLevel(conf)
+		Expect(buf.Len()).To(BeZero())
+
+		conf.Spec.Configuration[0].DumpTrigger = "second"
+		lib.UpdateLogLevel(conf)
+		Expect(buf.String()).To(ContainSubstring("before trigger"))
+	})
+
+	It("keeps records up to the verbosity set by mappings and custom levels", func() {
+		verbose := int32(lib.LogVerbose + 2)
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Mapping: &v1alpha1.VerbosityMapping{Verbose: &verbose},
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelInfo},
+				},
+			},
+		}
+		lib.UpdateLogLevel(conf)
+
+		logger := instance.NewFlightRecorderLogger(klogr.New(), 10)
+		var buf bytes.Buffer
+		instance.SetFlightRecorderOutput(&buf)
+
+		logger.V(int(verbose)).Info("mapped verbose record")
+		logger.V(int(verbose) + 1).Info("ignored")
+		logger.Error(errors.New("failure"), "reconciliation failed")
+		Expect(buf.String()).To(ContainSubstring("mapped verbose record"))
+		Expect(buf.String()).ToNot(ContainSubstring("ignored"))
+
+		conf.Spec.CustomLevels = []v1alpha1.CustomLevel{{Name: "trace", Verbosity: verbose + 5}}
+		lib.UpdateLogLevel(conf)
+		buf.Reset()
+		logger.V(int(verbose) + 5).Info("trace record")
+		logger.Error(errors.New("failure"), "reconciliation failed")
+		Expect(buf.String()).To(ContainSubstring("trace record"))
+
+		instance.CloseFlightRecorder(logger)
+	})
+
+	It("releases closed and unreferenced flight recorders", func() {
+		logger := instance.NewFlightRecorderLogger(klogr.New(), 10)
+		id := lib.FlightRecorderID(logger)
+		Expect(instance.HasFlightRecorder(id)).To(BeTrue())
+		var buf bytes.Buffer
+		instance.SetFlightRecorderOutput(&buf)
+
+		instance.CloseFlightRecorder(logger.WithName("reconciler"))
+		Expect(instance.HasFlightRecorder(id)).To(BeFalse())
+		logger.V(lib.LogDebug).Info("after close")
+		logger.Error(errors.New("failure"), "reconciliation failed")
+		Expect(buf.Len()).To(BeZero())
+
+		id = func() uintptr {
+			unreferenced := instance.NewFlightRecorderLogger(klogr.New(), 10)
+			unreferenced.WithValues("attempt", 1).V(lib.LogDebug).Info("record")
+			return lib.FlightRecorderID(unreferenced)
+		}()
+		Eventually(func() bool {
+			runtime.GC()
+			return instance.HasFlightRecorder(id)
+		}, 5*time.Second, 10*time.Millisecond).Should(BeFalse())
+	})
+})
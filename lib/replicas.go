@@ -0,0 +1,105 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"encoding/json"
+	"hash/fnv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// ReplicaSelection reports whether this replica is selected by a LogSetting
+// entry limited to a percentage of replicas
+type ReplicaSelection struct {
+	// Index of the entry in LogSetting Spec.Configuration
+	Index int
+
+	// Percentage of replicas the entry is limited to
+	Percentage int32
+
+	// Selected is true if this replica is in the sample
+	Selected bool
+}
+
+// setReplica sets the name of the Pod the component runs in and applies
+// current configuration again
+func (l *LogSetter) setReplica(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.replica = name
+	l.reapply()
+}
+
+// ReplicaSelections returns, for each entry of last LogSetting processed which
+// matches the component and is limited to a percentage of replicas, whether
+// this replica is selected. Such entries apply only when it is.
+func (l *LogSetter) ReplicaSelections() []ReplicaSelection {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.logSetting == nil {
+		return nil
+	}
+
+	var selections []ReplicaSelection
+	target := l.target()
+	for i := range l.logSetting.Spec.Configuration {
+		c := &l.logSetting.Spec.Configuration[i]
+		if c.ReplicaPercentage == nil {
+			continue
+		}
+		if _, ok := match(c, target); ok {
+			selections = append(selections, ReplicaSelection{
+				Index: i, Percentage: *c.ReplicaPercentage, Selected: inSample(c, target.Replica),
+			})
+		}
+	}
+	return selections
+}
+
+// inSample returns true if c is not limited to a percentage of replicas or
+// replica is among the ones selected. Selection hashes replica with the fields
+// of c selecting components, so it does not change when, for instance, log
+// level is changed.
+func inSample(c *v1alpha1.ComponentConfiguration, replica string) bool {
+	if c.ReplicaPercentage == nil {
+		return true
+	}
+	if replica == "" {
+		return false
+	}
+
+	key, err := json.Marshal(struct {
+		Component    v1alpha1.Component    `json:"component"`
+		TagSelector  *metav1.LabelSelector `json:"tagSelector,omitempty"`
+		Nodes        []string              `json:"nodes,omitempty"`
+		NodeSelector *metav1.LabelSelector `json:"nodeSelector,omitempty"`
+	}{c.Component, c.TagSelector, c.Nodes, c.NodeSelector})
+	if err != nil {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(replica))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write(key)
+	return int64(h.Sum32()%100) < int64(*c.ReplicaPercentage)
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"fmt"
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Replica percentage", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	// selected returns the replicas selected by conf
+	selected := func(conf *v1alpha1.LogSetting, replicas []string) []string {
+		var result []string
+		for _, replica := range replicas {
+			if len(lib.Resolve(conf, &lib.Target{Component: component, Replica: replica})) != 0 {
+				result = append(result, replica)
+			}
+		}
+		return result
+	}
+
+	replicas := make([]string, 100)
+	for i := range replicas {
+		replicas[i] = fmt.Sprintf("%s-6d4cf56db6-%d", componentIdentifier, i)
+	}
+
+	newLogSetting := func(percentage int32, level v1alpha1.LogLevel) *v1alpha1.LogSetting {
+		return &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, ReplicaPercentage: pointer.Int32(percentage), LogLevel: level},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		instance.SetReplica("")
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("selects replicas deterministically", func() {
+		sample := selected(newLogSetting(10, v1alpha1.LogLevelVerbose), replicas)
+		Expect(len(sample)).To(BeNumerically(">", 0))
+		Expect(len(sample)).To(BeNumerically("<", 30))
+
+		// Same replicas stay selected, even when log level changes
+		Expect(selected(newLogSetting(10, v1alpha1.LogLevelVerbose), replicas)).To(Equal(sample))
+		Expect(selected(newLogSetting(10, v1alpha1.LogLevelDebug), replicas)).To(Equal(sample))
+
+		// Raising percentage only adds replicas
+		Expect(selected(newLogSetting(50, v1alpha1.LogLevelVerbose), replicas)).To(ContainElements(sample))
+
+		Expect(selected(newLogSetting(0, v1alpha1.LogLevelVerbose), replicas)).To(BeEmpty())
+		Expect(selected(newLogSetting(100, v1alpha1.LogLevelVerbose), replicas)).To(Equal(replicas))
+
+		// Replica must be known
+		Expect(selected(newLogSetting(100, v1alpha1.LogLevelVerbose), []string{""})).To(BeEmpty())
+	})
+
+	It("applies entry only on selected replicas and reports selection", func() {
+		conf := newLogSetting(10, v1alpha1.LogLevelVerbose)
+		sample := selected(conf, replicas)
+		Expect(sample).ToNot(BeEmpty())
+		notSelected := ""
+		for _, replica := range replicas {
+			if len(selected(conf, []string{replica})) == 0 {
+				notSelected = replica
+				break
+			}
+		}
+		Expect(notSelected).ToNot(BeEmpty())
+
+		lib.UpdateLogLevel(conf)
+
+		instance.SetReplica(sample[0])
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+		Expect(instance.ReplicaSelections()).To(Equal([]lib.ReplicaSelection{
+			{Index: 0, Percentage: 10, Selected: true},
+		}))
+
+		instance.SetReplica(notSelected)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogInfo)))
+		Expect(instance.ReplicaSelections()).To(Equal([]lib.ReplicaSelection{
+			{Index: 0, Percentage: 10, Selected: false},
+		}))
+	})
+})
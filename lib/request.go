@@ -0,0 +1,192 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+const (
+	// DebugTokenHeader is the HTTP header carrying the debug token
+	DebugTokenHeader = "X-Debug-Token"
+
+	// defaultDebugTokenKey is the Secret data key used when RequestDebug
+	// does not set one
+	defaultDebugTokenKey = "token"
+
+	// debugTokenTTL is how long a debug token read from a Secret is cached
+	debugTokenTTL = time.Minute
+
+	// debugTokenErrorTTL is how long a failure reading the debug token is
+	// cached. Till then, requests carrying a debug token are not elevated.
+	debugTokenErrorTTL = 10 * time.Second
+
+	// debugTokenTimeout bounds the read of the debug token Secret
+	debugTokenTimeout = 10 * time.Second
+)
+
+// debugToken is a debug token read from a Secret, or the error reading it.
+// token, err and expiry are set before done is closed and never change after.
+type debugToken struct {
+	secretRef corev1.SecretReference
+	key       string
+	token     []byte
+	err       error
+	expiry    time.Time
+
+	// done is closed once the Secret is read
+	done chan struct{}
+}
+
+// expired returns true if t was read and it is now stale. A token still
+// being read is not.
+func (t *debugToken) expired(now time.Time) bool {
+	select {
+	case <-t.done:
+		return now.After(t.expiry)
+	default:
+		return false
+	}
+}
+
+// HTTPMiddleware returns a handler which, for requests carrying a valid debug
+// token in DebugTokenHeader, stores an elevated logger in request context.
+// Such logger can be retrieved with logr.FromContext. Other requests are
+// passed to next unchanged.
+func (l *LogSetter) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if logger, ok := l.RequestLogger(r.Context(), r.Header.Get(DebugTokenHeader)); ok {
+			r = r.WithContext(logr.NewContext(r.Context(), logger))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequestLogger returns the elevated logger for a request carrying token.
+// It returns false if token is not valid or request debugging is not
+// configured for this component. Like LoggerFor, the returned logger is based
+// on the component logger. Servers not using HTTPMiddleware, like the
+// gRPC interceptors in package grpclog, use it to elevate their requests.
+func (l *LogSetter) RequestLogger(ctx context.Context, token string) (logr.Logger, bool) {
+	if token == "" {
+		return logr.Logger{}, false
+	}
+
+	l.mu.Lock()
+	var requestDebug *v1alpha1.RequestDebug
+	if l.configuration != nil {
+		requestDebug = l.configuration.RequestDebug
+	}
+	if requestDebug == nil {
+		l.mu.Unlock()
+		return logr.Logger{}, false
+	}
+	level := requestDebug.LogLevel
+	if level == "" {
+		level = v1alpha1.LogLevelDebug
+	}
+	_, value, ok := l.logLevelValue(l.configuration, level)
+	secretRef := requestDebug.SecretRef
+	key := requestDebug.Key
+	if key == "" {
+		key = defaultDebugTokenKey
+	}
+	l.mu.Unlock()
+
+	if !ok {
+		return logr.Logger{}, false
+	}
+
+	expected, err := l.cachedDebugToken(ctx, secretRef, key)
+	if err != nil {
+		return logr.Logger{}, false
+	}
+
+	if len(expected) == 0 || subtle.ConstantTimeCompare(expected, []byte(token)) != 1 {
+		return logr.Logger{}, false
+	}
+
+	sink := &elevationSink{sink: wrapSink(l.componentLogger.GetSink()), level: verbosity(value)}
+	return logr.New(sink).WithValues("debugRequest", true), true
+}
+
+// cachedDebugToken returns the debug token in Secret secretRef. Secret is read
+// at most once per debugTokenTTL (debugTokenErrorTTL on failures): concurrent
+// requests wait for the same read.
+func (l *LogSetter) cachedDebugToken(ctx context.Context, secretRef corev1.SecretReference,
+	key string) ([]byte, error) {
+
+	l.mu.Lock()
+	t := l.debugToken
+	if t == nil || t.secretRef != secretRef || t.key != key || t.expired(time.Now()) {
+		t = &debugToken{secretRef: secretRef, key: key, done: make(chan struct{})}
+		l.debugToken = t
+		go l.readDebugToken(t)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return t.token, t.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// readDebugToken reads t from its Secret
+func (l *LogSetter) readDebugToken(t *debugToken) {
+	ctx, cancel := context.WithTimeout(context.Background(), debugTokenTimeout)
+	defer cancel()
+
+	t.token, t.err = l.getDebugToken(ctx, t.secretRef, t.key)
+	ttl := debugTokenTTL
+	if t.err != nil {
+		l.logger.Error(t.err, "failed to get debug token")
+		ttl = debugTokenErrorTTL
+	}
+	t.expiry = time.Now().Add(ttl)
+	close(t.done)
+}
+
+// getDebugToken reads the debug token from Secret
+func (l *LogSetter) getDebugToken(ctx context.Context, secretRef corev1.SecretReference, key string) ([]byte, error) {
+	clientset, err := l.kubernetesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := clientset.CoreV1().Secrets(secretRef.Namespace).Get(ctx, secretRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	token, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %s", secretRef.Namespace, secretRef.Name, key)
+	}
+	return token, nil
+}
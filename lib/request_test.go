@@ -0,0 +1,190 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("RequestDebug", func() {
+	const token = "s3cr3t"
+	secretRef := corev1.SecretReference{Namespace: "projectsveltos", Name: "debug-token"}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component:    component,
+						LogLevel:     v1alpha1.LogLevelInfo,
+						RequestDebug: &v1alpha1.RequestDebug{SecretRef: secretRef},
+					},
+				},
+			},
+		})
+		instance.SetDebugToken(secretRef, "token", token)
+	})
+
+	// debugEnabled returns whether ctx carries a logger with debug enabled
+	debugEnabled := func(ctx context.Context) bool {
+		logger, err := logr.FromContext(ctx)
+		if err != nil {
+			return false
+		}
+		return logger.V(lib.LogDebug).Enabled()
+	}
+
+	It("HTTPMiddleware elevates requests carrying a valid debug token", func() {
+		var elevated bool
+		handler := instance.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			elevated = debugEnabled(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set(lib.DebugTokenHeader, token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(elevated).To(BeTrue())
+
+		req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set(lib.DebugTokenHeader, "wrong")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(elevated).To(BeFalse())
+
+		req = httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(elevated).To(BeFalse())
+
+		// Global verbosity is unchanged
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+	})
+
+	It("RequestLogger elevates requests carrying a valid debug token", func() {
+		logger, ok := instance.RequestLogger(context.TODO(), token)
+		Expect(ok).To(BeTrue())
+		Expect(logger.V(lib.LogDebug).Enabled()).To(BeTrue())
+
+		_, ok = instance.RequestLogger(context.TODO(), "wrong")
+		Expect(ok).To(BeFalse())
+
+		_, ok = instance.RequestLogger(context.TODO(), "")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not elevate requests when RequestDebug is not set", func() {
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelInfo},
+				},
+			},
+		})
+
+		var elevated bool
+		handler := instance.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			elevated = debugEnabled(r.Context())
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+		req.Header.Set(lib.DebugTokenHeader, token)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		Expect(elevated).To(BeFalse())
+	})
+
+	It("reads debug token Secret once, even when reading fails", func() {
+		var gets int32
+		clientset := fake.NewSimpleClientset()
+		clientset.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			atomic.AddInt32(&gets, 1)
+			return false, nil, nil
+		})
+		previous := instance.SetClientset(clientset)
+		defer instance.SetClientset(previous)
+		instance.ClearDebugToken()
+		defer instance.ClearDebugToken()
+
+		var elevated int32
+		handler := instance.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if debugEnabled(r.Context()) {
+				atomic.AddInt32(&elevated, 1)
+			}
+		}))
+		serve := func(n int) {
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req := httptest.NewRequest(http.MethodGet, "/", http.NoBody)
+					req.Header.Set(lib.DebugTokenHeader, token)
+					handler.ServeHTTP(httptest.NewRecorder(), req)
+				}()
+			}
+			wg.Wait()
+		}
+
+		// Secret does not exist: failure is cached
+		serve(20)
+		serve(20)
+		Expect(atomic.LoadInt32(&gets)).To(Equal(int32(1)))
+		Expect(atomic.LoadInt32(&elevated)).To(BeZero())
+
+		// Once cached, a valid token does not need any read
+		_, err := clientset.CoreV1().Secrets(secretRef.Namespace).Create(context.TODO(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: secretRef.Namespace, Name: secretRef.Name},
+			Data:       map[string][]byte{"token": []byte(token)},
+		}, metav1.CreateOptions{})
+		Expect(err).To(BeNil())
+		instance.ClearDebugToken()
+		serve(20)
+		serve(20)
+		Expect(atomic.LoadInt32(&gets)).To(Equal(int32(2)))
+		Expect(atomic.LoadInt32(&elevated)).To(Equal(int32(40)))
+	})
+})
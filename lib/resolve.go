@@ -0,0 +1,235 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"path"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// AnyNamespace, used as Component Namespace, makes a LogSetting entry match
+// components in any namespace. With Identifier "*" as well, the entry is the
+// cluster-wide default.
+const AnyNamespace = "*"
+
+// MatchKind is how a LogSetting entry matches a component. Kinds are listed
+// from the most to the least specific.
+type MatchKind string
+
+const (
+	// MatchComponent is an entry for the exact component
+	MatchComponent = MatchKind("component")
+
+	// MatchTags is an entry whose TagSelector matches component tags
+	MatchTags = MatchKind("tags")
+
+	// MatchNamespace is an entry whose Identifier is a glob pattern, like
+	// "capi-*" or "*", matching component in its namespace
+	MatchNamespace = MatchKind("namespace")
+
+	// MatchCluster is an entry for AnyNamespace whose Identifier pattern
+	// matches the component
+	MatchCluster = MatchKind("cluster")
+)
+
+// specificity of each MatchKind. The higher, the more specific.
+var specificity = map[MatchKind]int{
+	MatchComponent: 4,
+	MatchTags:      3,
+	MatchNamespace: 2,
+	MatchCluster:   1,
+}
+
+// Target is a registered component LogSetting entries are matched against
+type Target struct {
+	// Component is the registered component
+	Component v1alpha1.Component
+
+	// Tags the component is registered with
+	Tags map[string]string
+
+	// Node the component runs on, if known
+	Node string
+
+	// NodeLabels are the labels of Node
+	NodeLabels map[string]string
+
+	// Replica is the name of the Pod the component runs in, if known. It is
+	// needed by entries limited to a percentage of replicas.
+	Replica string
+}
+
+// Match is a LogSetting entry matching a Target
+type Match struct {
+	// Index of the entry in LogSetting Spec.Configuration
+	Index int
+
+	// Kind is how the entry matches
+	Kind MatchKind
+
+	// NodeScoped is true if the entry is limited to nodes. Among entries of
+	// the same kind, one limited to nodes is more specific.
+	NodeScoped bool
+
+	// ReplicaScoped is true if the entry is limited to a percentage of
+	// replicas. Among entries of the same kind equally limited to nodes, one
+	// limited to a percentage of replicas is more specific.
+	ReplicaScoped bool
+
+	// literals is the number of literal characters in the Identifier
+	// pattern. Among pattern matches of the same kind, more is more specific.
+	literals int
+}
+
+// Resolve returns the entries of d matching target, from the one which applies
+// to the least specific one. An entry for the exact component beats a tag
+// match, which beats a glob Identifier in component namespace, which beats an
+// entry for AnyNamespace. For the same kind, an entry limited to nodes beats
+// one which is not, then an entry limited to a percentage of replicas beats
+// one which is not, and a pattern with more literal characters ("capi-*")
+// beats one with less ("*"). Among equally specific entries, the last one in
+// d wins.
+// Entries limited to a percentage of replicas are returned only if target
+// Replica is selected. Entries are returned regardless of their schedule: a
+// LogSetter skips entries not currently scheduled, falling back to the next one.
+func Resolve(d *v1alpha1.LogSetting, target *Target) []Match {
+	var matches []Match
+	for i := range d.Spec.Configuration {
+		c := &d.Spec.Configuration[i]
+		if m, ok := match(c, target); ok && inSample(c, target.Replica) {
+			m.Index = i
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Kind != matches[j].Kind {
+			return specificity[matches[i].Kind] > specificity[matches[j].Kind]
+		}
+		if matches[i].NodeScoped != matches[j].NodeScoped {
+			return matches[i].NodeScoped
+		}
+		if matches[i].ReplicaScoped != matches[j].ReplicaScoped {
+			return matches[i].ReplicaScoped
+		}
+		if matches[i].literals != matches[j].literals {
+			return matches[i].literals > matches[j].literals
+		}
+		return matches[i].Index > matches[j].Index
+	})
+	return matches
+}
+
+// match returns how c matches target, if it does. Whether target replica is
+// selected, for entries limited to a percentage of replicas, is not evaluated.
+func match(c *v1alpha1.ComponentConfiguration, target *Target) (Match, bool) {
+	if !matchNode(c, target) {
+		return Match{}, false
+	}
+
+	m, ok := matchComponent(c, target)
+	m.NodeScoped = len(c.Nodes) != 0 || c.NodeSelector != nil
+	m.ReplicaScoped = c.ReplicaPercentage != nil
+	return m, ok
+}
+
+// matchNode returns true if c is not limited to nodes or target runs on a
+// node c is limited to
+func matchNode(c *v1alpha1.ComponentConfiguration, target *Target) bool {
+	if len(c.Nodes) != 0 {
+		found := false
+		for i := range c.Nodes {
+			if c.Nodes[i] == target.Node {
+				found = true
+				break
+			}
+		}
+		if !found || target.Node == "" {
+			return false
+		}
+	}
+
+	if c.NodeSelector != nil {
+		if target.Node == "" {
+			return false
+		}
+		selector, err := metav1.LabelSelectorAsSelector(c.NodeSelector)
+		if err != nil || !selector.Matches(labels.Set(target.NodeLabels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchComponent returns how c matches target component, if it does
+func matchComponent(c *v1alpha1.ComponentConfiguration, target *Target) (Match, bool) {
+	if c.TagSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(c.TagSelector)
+		if err != nil || !selector.Matches(labels.Set(target.Tags)) {
+			return Match{}, false
+		}
+		return Match{Kind: MatchTags}, true
+	}
+
+	if c.Component == target.Component {
+		return Match{Kind: MatchComponent}, true
+	}
+
+	kind := MatchNamespace
+	if c.Component.Namespace == AnyNamespace {
+		kind = MatchCluster
+	} else if c.Component.Namespace != target.Component.Namespace {
+		return Match{}, false
+	}
+	if matched, err := path.Match(c.Component.Identifier, target.Component.Identifier); err != nil || !matched {
+		return Match{}, false
+	}
+	return Match{Kind: kind, literals: countLiterals(c.Component.Identifier)}, true
+}
+
+// countLiterals returns the number of characters of glob pattern matched
+// literally
+func countLiterals(pattern string) int {
+	literals := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*', '?':
+		case '[':
+			// A character class matches a single, not literal, character
+			for i < len(pattern) && pattern[i] != ']' {
+				i++
+			}
+		case '\\':
+			i++
+			literals++
+		default:
+			literals++
+		}
+	}
+	return literals
+}
+
+// target returns the Target LogSetting entries are matched against
+func (l *LogSetter) target() *Target {
+	return &Target{Component: l.component, Tags: l.tags, Node: l.nodeName, NodeLabels: l.nodeLabels,
+		Replica: l.replica}
+}
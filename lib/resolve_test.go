@@ -0,0 +1,141 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Resolve", func() {
+	target := &lib.Target{
+		Component: v1alpha1.Component{Namespace: "projectsveltos", Identifier: "capi-controller"},
+		Tags:      map[string]string{"team": "payments"},
+	}
+
+	entry := func(namespace, identifier string) v1alpha1.ComponentConfiguration {
+		return v1alpha1.ComponentConfiguration{
+			Component: v1alpha1.Component{Namespace: namespace, Identifier: identifier},
+			LogLevel:  v1alpha1.LogLevelDebug,
+		}
+	}
+
+	It("orders matching entries from the most to the least specific", func() {
+		conf := &v1alpha1.LogSetting{
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					entry("*", "*"),
+					entry("projectsveltos", "capi-controller"),
+					entry("projectsveltos", "*"),
+					{
+						TagSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "payments"}},
+						LogLevel:    v1alpha1.LogLevelDebug,
+					},
+					entry("projectsveltos", "capi-*"),
+					entry("projectsveltos", "addon-*"),
+					entry("kube-system", "*"),
+					entry("*", "capi-*"),
+				},
+			},
+		}
+
+		matches := lib.Resolve(conf, target)
+		indexes := make([]int, len(matches))
+		kinds := make([]lib.MatchKind, len(matches))
+		for i := range matches {
+			indexes[i] = matches[i].Index
+			kinds[i] = matches[i].Kind
+		}
+		Expect(indexes).To(Equal([]int{1, 3, 4, 2, 7, 0}))
+		Expect(kinds).To(Equal([]lib.MatchKind{lib.MatchComponent, lib.MatchTags, lib.MatchNamespace,
+			lib.MatchNamespace, lib.MatchCluster, lib.MatchCluster}))
+	})
+
+	It("gives precedence to the last of equally specific entries", func() {
+		conf := &v1alpha1.LogSetting{
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					entry("projectsveltos", "capi-*"),
+					entry("projectsveltos", "*oller"),
+				},
+			},
+		}
+
+		matches := lib.Resolve(conf, target)
+		Expect(len(matches)).To(Equal(2))
+		Expect(matches[0].Index).To(Equal(1))
+	})
+
+	It("ignores entries with invalid patterns", func() {
+		conf := &v1alpha1.LogSetting{
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					entry("projectsveltos", "capi-["),
+				},
+			},
+		}
+
+		Expect(lib.Resolve(conf, target)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Wildcard entries", func() {
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("applies the most specific entry matching the component", func() {
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: v1alpha1.Component{Namespace: componentNamespace, Identifier: "*"},
+						LogLevel:  v1alpha1.LogLevelVerbose,
+					},
+					{
+						Component: v1alpha1.Component{Namespace: lib.AnyNamespace, Identifier: "*"},
+						LogLevel:  v1alpha1.LogLevelDebug,
+					},
+				},
+			},
+		}
+		lib.UpdateLogLevel(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+
+		// Cluster-wide default applies when nothing more specific matches
+		conf.Spec.Configuration = conf.Spec.Configuration[1:]
+		lib.UpdateLogLevel(conf)
+		Expect(instance.GetVerbosity()).To(Equal(strconv.Itoa(lib.LogDebug)))
+	})
+})
@@ -0,0 +1,139 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// callSiteCounter counts records logged by a call site within a second
+type callSiteCounter struct {
+	second int64
+	count  int64
+}
+
+// sampler keeps per call site counters shared by a sampling logger and all
+// loggers derived from it.
+type sampler struct {
+	mu       sync.Mutex
+	counters map[uintptr]*callSiteCounter
+	now      func() time.Time
+}
+
+func newSampler() *sampler {
+	return &sampler{
+		counters: make(map[uintptr]*callSiteCounter),
+		now:      time.Now,
+	}
+}
+
+// keep returns true if the record logged at call site pc must be logged
+// given first and thereafter
+func (s *sampler) keep(pc uintptr, first, thereafter int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	second := s.now().Unix()
+	counter, ok := s.counters[pc]
+	if !ok {
+		counter = &callSiteCounter{}
+		s.counters[pc] = counter
+	}
+	if counter.second != second {
+		counter.second = second
+		counter.count = 0
+	}
+	counter.count++
+
+	if counter.count <= first {
+		return true
+	}
+	if thereafter <= 0 {
+		return false
+	}
+	return (counter.count-first)%thereafter == 0
+}
+
+// samplingSink is a LogSink which samples records above Info based on the
+// Sampling policy of the LogSetting entry for this component.
+type samplingSink struct {
+	sink    logr.LogSink
+	setter  *LogSetter
+	sampler *sampler
+	depth   int
+}
+
+// NewSamplingLogger returns a logger which enforces the Sampling policy of the
+// LogSetting entry for this component, if any, on debug and verbose records.
+// Info records and errors are never dropped.
+func (l *LogSetter) NewSamplingLogger(logger logr.Logger) logr.Logger {
+	return logr.New(&samplingSink{sink: wrapSink(logger.GetSink()), setter: l, sampler: newSampler()})
+}
+
+// Init is a no-op: wrapped sink is already initialized.
+func (s *samplingSink) Init(info logr.RuntimeInfo) {
+}
+
+func (s *samplingSink) Enabled(level int) bool {
+	return s.sink.Enabled(level)
+}
+
+func (s *samplingSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	if level > 0 && !s.keep() {
+		return
+	}
+	s.sink.Info(level, msg, keysAndValues...)
+}
+
+// keep returns true if the record being logged must not be dropped
+func (s *samplingSink) keep() bool {
+	sampling := s.setter.published().sampling
+	if sampling == nil {
+		return true
+	}
+
+	// Skip keep, Info and logr.Logger.Info to reach the call site
+	pc, _, _, ok := runtime.Caller(3 + s.depth)
+	if !ok {
+		return true
+	}
+
+	return s.sampler.keep(pc, int64(sampling.First), int64(sampling.Thereafter))
+}
+
+func (s *samplingSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	s.sink.Error(err, msg, keysAndValues...)
+}
+
+func (s *samplingSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &samplingSink{sink: s.sink.WithValues(keysAndValues...), setter: s.setter, sampler: s.sampler,
+		depth: s.depth}
+}
+
+func (s *samplingSink) WithName(name string) logr.LogSink {
+	return &samplingSink{sink: s.sink.WithName(name), setter: s.setter, sampler: s.sampler,
+		depth: s.depth}
+}
+
+func (s *samplingSink) WithCallDepth(depth int) logr.LogSink {
+	return &samplingSink{sink: withCallDepth(s.sink, depth), setter: s.setter, sampler: s.sampler,
+		depth: s.depth + depth}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Sampling", func() {
+	var records []string
+	var now time.Time
+	var logger logr.Logger
+
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+
+		records = nil
+		now = time.Date(2023, time.May, 1, 10, 0, 0, 0, time.UTC)
+		sink := funcr.New(func(prefix, args string) {
+			records = append(records, args)
+		}, funcr.Options{Verbosity: lib.LogVerbose})
+		logger = instance.NewSamplingLoggerWithClock(sink, func() time.Time { return now })
+	})
+
+	updateSampling := func(sampling *v1alpha1.Sampling) {
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{Component: component, LogLevel: v1alpha1.LogLevelVerbose, Sampling: sampling},
+				},
+			},
+		})
+	}
+
+	It("logs first records per second per call site, then one every thereafter", func() {
+		updateSampling(&v1alpha1.Sampling{First: 3, Thereafter: 4})
+
+		for i := 0; i < 10; i++ {
+			logger.V(lib.LogDebug).Info("hot path")
+		}
+		// Records 1, 2, 3 and 7 are kept
+		Expect(len(records)).To(Equal(4))
+
+		// Different call site has its own counter
+		for i := 0; i < 3; i++ {
+			logger.WithName("other").V(lib.LogVerbose).Info("other path")
+		}
+		Expect(len(records)).To(Equal(7))
+
+		// Counters are reset every second
+		now = now.Add(time.Second)
+		for i := 0; i < 3; i++ {
+			logger.V(lib.LogDebug).Info("hot path")
+		}
+		Expect(len(records)).To(Equal(10))
+	})
+
+	It("never drops info records", func() {
+		updateSampling(&v1alpha1.Sampling{First: 1, Thereafter: 100})
+
+		for i := 0; i < 10; i++ {
+			logger.Info("info")
+		}
+		Expect(len(records)).To(Equal(10))
+	})
+
+	It("does not sample when policy is not set", func() {
+		updateSampling(nil)
+
+		for i := 0; i < 10; i++ {
+			logger.V(lib.LogDebug).Info("hot path")
+		}
+		Expect(len(records)).To(Equal(10))
+	})
+})
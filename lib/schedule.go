@@ -0,0 +1,122 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// IsScheduled returns whether schedule is active at now. An invalid schedule
+// is never active: LogSetting entries with such a schedule are skipped.
+func IsScheduled(schedule *v1alpha1.Schedule, now time.Time) (bool, error) {
+	active, _, err := evaluateSchedule(schedule, now)
+	return active && err == nil, err
+}
+
+// evaluateSchedule returns whether schedule is active at now and the time
+// schedule will next change (becoming active or inactive).
+func evaluateSchedule(schedule *v1alpha1.Schedule, now time.Time) (active bool, next time.Time, err error) {
+	location := time.UTC
+	if schedule.TimeZone != "" {
+		location, err = time.LoadLocation(schedule.TimeZone)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+	}
+	now = now.In(location)
+
+	if schedule.Cron != "" {
+		return evaluateCron(schedule, now)
+	}
+
+	if schedule.Start != "" && schedule.End != "" {
+		return evaluateDailyWindow(schedule, now)
+	}
+
+	return false, time.Time{}, fmt.Errorf("schedule must have either cron and duration or start and end")
+}
+
+// evaluateCron evaluates a schedule defined by a cron expression and a duration.
+// Schedule is active if an activation happened in the last Duration.
+func evaluateCron(schedule *v1alpha1.Schedule, now time.Time) (active bool, next time.Time, err error) {
+	if schedule.Duration == nil || schedule.Duration.Duration <= 0 {
+		return false, time.Time{}, fmt.Errorf("schedule with cron requires a positive duration")
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule.Cron)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	duration := schedule.Duration.Duration
+	// First activation after now - duration. If that is not after now, schedule
+	// is currently active.
+	start := cronSchedule.Next(now.Add(-duration))
+	if start.After(now) {
+		return false, start, nil
+	}
+
+	return true, start.Add(duration), nil
+}
+
+// evaluateDailyWindow evaluates a schedule defined by a daily time window.
+func evaluateDailyWindow(schedule *v1alpha1.Schedule, now time.Time) (active bool, next time.Time, err error) {
+	start, err := timeOfDay(schedule.Start, now)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	end, err := timeOfDay(schedule.End, now)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+
+	if end.After(start) {
+		switch {
+		case now.Before(start):
+			return false, start, nil
+		case now.Before(end):
+			return true, end, nil
+		default:
+			return false, start.AddDate(0, 0, 1), nil
+		}
+	}
+
+	// Window spans midnight
+	switch {
+	case now.Before(end):
+		return true, end, nil
+	case now.Before(start):
+		return false, start, nil
+	default:
+		return true, end.AddDate(0, 0, 1), nil
+	}
+}
+
+// timeOfDay returns the time at clock (HH:MM) on the same day as now.
+func timeOfDay(clock string, now time.Time) (time.Time, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"flag"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Schedule", func() {
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("evaluates cron schedules", func() {
+		schedule := &v1alpha1.Schedule{
+			Cron:     "0 2 * * *",
+			Duration: &metav1.Duration{Duration: time.Hour},
+		}
+
+		now := time.Date(2023, time.May, 10, 1, 30, 0, 0, time.UTC)
+		active, next, err := lib.EvaluateSchedule(schedule, now)
+		Expect(err).To(BeNil())
+		Expect(active).To(BeFalse())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 10, 2, 0, 0, 0, time.UTC)))
+
+		active, next, err = lib.EvaluateSchedule(schedule, next)
+		Expect(err).To(BeNil())
+		Expect(active).To(BeTrue())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 10, 3, 0, 0, 0, time.UTC)))
+
+		active, next, err = lib.EvaluateSchedule(schedule, next)
+		Expect(err).To(BeNil())
+		Expect(active).To(BeFalse())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 11, 2, 0, 0, 0, time.UTC)))
+
+		schedule.Duration = nil
+		_, _, err = lib.EvaluateSchedule(schedule, now)
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("evaluates daily windows in the given time zone", func() {
+		schedule := &v1alpha1.Schedule{
+			Start:    "22:00",
+			End:      "04:00",
+			TimeZone: "Europe/Rome",
+		}
+
+		rome, err := time.LoadLocation("Europe/Rome")
+		Expect(err).To(BeNil())
+
+		now := time.Date(2023, time.May, 10, 23, 0, 0, 0, rome)
+		active, next, err := lib.EvaluateSchedule(schedule, now)
+		Expect(err).To(BeNil())
+		Expect(active).To(BeTrue())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 11, 4, 0, 0, 0, rome)))
+
+		now = time.Date(2023, time.May, 10, 12, 0, 0, 0, rome)
+		active, next, err = lib.EvaluateSchedule(schedule, now.UTC())
+		Expect(err).To(BeNil())
+		Expect(active).To(BeFalse())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 10, 22, 0, 0, 0, rome)))
+
+		schedule.Start = "08:00"
+		schedule.End = "18:00"
+		active, next, err = lib.EvaluateSchedule(schedule, now)
+		Expect(err).To(BeNil())
+		Expect(active).To(BeTrue())
+		Expect(next).To(BeTemporally("==", time.Date(2023, time.May, 10, 18, 0, 0, 0, rome)))
+	})
+
+	It("scheduled configuration applies only within its window", func() {
+		component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+		now := time.Now().UTC()
+
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: component,
+						LogLevel:  v1alpha1.LogLevelVerbose,
+						Schedule: &v1alpha1.Schedule{
+							Start: now.Add(2 * time.Hour).Format("15:04"),
+							End:   now.Add(3 * time.Hour).Format("15:04"),
+						},
+					},
+				},
+			},
+		}
+
+		instance.SetDefaultValue(lib.LogInfo)
+		lib.UpdateLogLevel(conf)
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogInfo)))
+
+		conf.Spec.Configuration[0].Schedule = &v1alpha1.Schedule{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+		}
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogVerbose)))
+	})
+
+	It("settings are taken from the entry which applies", func() {
+		now := time.Now().UTC()
+		debugVerbosity := int32(7)
+
+		conf := &v1alpha1.LogSetting{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "default",
+			},
+			Spec: v1alpha1.LogSettingSpec{
+				Configuration: []v1alpha1.ComponentConfiguration{
+					{
+						Component: v1alpha1.Component{Namespace: lib.AnyNamespace, Identifier: "*"},
+						LogLevel:  v1alpha1.LogLevelDebug,
+					},
+					{
+						Component: v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier},
+						LogLevel:  v1alpha1.LogLevelDebug,
+						Mapping:   &v1alpha1.VerbosityMapping{Debug: &debugVerbosity},
+						Schedule: &v1alpha1.Schedule{
+							Start: now.Add(2 * time.Hour).Format("15:04"),
+							End:   now.Add(3 * time.Hour).Format("15:04"),
+						},
+					},
+				},
+			},
+		}
+
+		// Mapping of the entry not scheduled is ignored
+		instance.SetDefaultValue(lib.LogInfo)
+		lib.UpdateLogLevel(conf)
+		f := flag.Lookup("v")
+		Expect(f).ToNot(BeNil())
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(lib.LogDebug)))
+
+		conf.Spec.Configuration[1].Schedule = &v1alpha1.Schedule{
+			Start: now.Add(-time.Hour).Format("15:04"),
+			End:   now.Add(time.Hour).Format("15:04"),
+		}
+		lib.UpdateLogLevel(conf)
+		Expect(f.Value.String()).To(Equal(strconv.Itoa(int(debugVerbosity))))
+	})
+})
@@ -0,0 +1,89 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+)
+
+// signalLevels are the log levels SIGUSR1 steps through
+var signalLevels = []v1alpha1.LogLevel{
+	v1alpha1.LogLevelInfo,
+	v1alpha1.LogLevelDebug,
+	v1alpha1.LogLevelVerbose,
+}
+
+// handleSignals changes log severity on signals received on ch, till ctx is done
+func (l *LogSetter) handleSignals(ctx context.Context, ch chan os.Signal) {
+	defer signal.Stop(ch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			if isRaiseSignal(sig) {
+				l.raiseSignalLevel()
+			} else {
+				l.resetSignalLevel()
+			}
+		}
+	}
+}
+
+// raiseSignalLevel sets log severity to the first of info, debug and verbose
+// more verbose than the current one
+func (l *LogSetter) raiseSignalLevel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d := l.logSetting
+	if d == nil {
+		d = &v1alpha1.LogSetting{}
+	}
+	// From a quiet level, info is a step up even if verbosity does not change
+	_, quiet := quietThreshold(l.level)
+	current := verbosity(flag.Lookup("v").Value.String())
+	for _, level := range signalLevels {
+		if _, value, _ := l.logLevelValue(l.configuration, level); quiet || verbosity(value) > current {
+			l.logger.Info("Got signal. Raising log severity", "logLevel", level)
+			l.signalLevel = level
+			l.updateLogLevel(d)
+			return
+		}
+	}
+	l.logger.Info("Got signal. Log severity is already at its maximum")
+}
+
+// resetSignalLevel sets log severity back to the one set by LogSetting
+func (l *LogSetter) resetSignalLevel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	d := l.logSetting
+	if d == nil {
+		d = &v1alpha1.LogSetting{}
+	}
+	l.logger.Info("Got signal. Resetting log severity to LogSetting one")
+	l.signalLevel = v1alpha1.LogLevelNotSet
+	l.updateLogLevel(d)
+}
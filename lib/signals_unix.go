@@ -0,0 +1,37 @@
+//go:build !windows
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifySignals returns a channel receiving signals changing log severity
+func notifySignals() chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1, syscall.SIGUSR2)
+	return ch
+}
+
+// isRaiseSignal returns true if sig raises log severity
+func isRaiseSignal(sig os.Signal) bool {
+	return sig == syscall.SIGUSR1
+}
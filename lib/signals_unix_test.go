@@ -0,0 +1,89 @@
+//go:build !windows
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	v1alpha1 "github.com/gianlucam76/pod-log-level/api/v1alpha1"
+	"github.com/gianlucam76/pod-log-level/lib"
+)
+
+var _ = Describe("Signal handlers", func() {
+	component := v1alpha1.Component{Namespace: componentNamespace, Identifier: componentIdentifier}
+
+	BeforeEach(func() {
+		instance.SetInfoValue(lib.LogInfo)
+		instance.SetDebugValue(lib.LogDebug)
+		instance.SetVerboseValue(lib.LogVerbose)
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	AfterEach(func() {
+		instance.ResetSignalLevel()
+		lib.UpdateLogLevel(&v1alpha1.LogSetting{})
+	})
+
+	It("changes log severity on SIGUSR1 and SIGUSR2", func() {
+		s := runtime.NewScheme()
+		Expect(v1alpha1.AddToScheme(s)).To(Succeed())
+		mgr, err := manager.New(cfg, manager.Options{
+			Scheme:             s,
+			MetricsBindAddress: "0",
+			NewCache: func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+				return &informertest.FakeInformers{Scheme: s}, nil
+			},
+			MapperProvider: func(_ *rest.Config, _ *http.Client) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = lib.SetupWithManager(mgr, component, lib.WithSignalHandlers())
+		Expect(err).ToNot(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		done := make(chan error)
+		go func() {
+			done <- mgr.Start(ctx)
+		}()
+
+		Expect(syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)).To(Succeed())
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogDebug)))
+
+		Expect(syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)).To(Succeed())
+		Eventually(instance.GetVerbosity).Should(Equal(strconv.Itoa(lib.LogInfo)))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+})
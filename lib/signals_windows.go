@@ -0,0 +1,34 @@
+//go:build windows
+
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"os"
+)
+
+// notifySignals returns a channel receiving signals changing log severity.
+// SIGUSR1 and SIGUSR2 do not exist on Windows: nothing is ever received.
+func notifySignals() chan os.Signal {
+	return make(chan os.Signal, 1)
+}
+
+// isRaiseSignal returns true if sig raises log severity
+func isRaiseSignal(sig os.Signal) bool {
+	return false
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// withCallDepth returns sink with depth added to its call depth, if sink
+// supports it.
+func withCallDepth(sink logr.LogSink, depth int) logr.LogSink {
+	if s, ok := sink.(logr.CallDepthLogSink); ok {
+		return s.WithCallDepth(depth)
+	}
+	return sink
+}
+
+// wrapSink prepares a sink to be wrapped by another LogSink. The wrapping sink
+// adds one frame between the caller and sink, so call depth is increased for
+// log call sites to still be correctly reported.
+func wrapSink(sink logr.LogSink) logr.LogSink {
+	return withCallDepth(sink, 1)
+}
@@ -0,0 +1,34 @@
+/*
+Copyright 2023.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lib
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// setTags sets tags the component is registered with and applies current
+// configuration again
+func (l *LogSetter) setTags(tags map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tags = make(labels.Set, len(tags))
+	for k, v := range tags {
+		l.tags[k] = v
+	}
+	l.reapply()
+}
@@ -43,6 +43,7 @@ func main() {
 	helper [options] <command> [<args>...]
 
     log-level      Allows changing the log verbosity.
+    completion     Generates shell completion scripts.
 
 Options:
 	-h --help          Show this screen.
@@ -59,8 +60,6 @@ Description:
 	klog.InitFlags(nil)
 
 	ctx := context.Background()
-	scheme, restConfig, clientSet, c := initializeManagementClusterAccess()
-	utils.InitalizeManagementClusterAcces(scheme, restConfig, clientSet, c)
 
 	parser := &docopt.Parser{
 		HelpHandler:   docopt.PrintHelpOnly,
@@ -88,7 +87,22 @@ Description:
 
 		switch command {
 		case "log-level":
+			scheme, restConfig, clientSet, c, accessErr := managementClusterAccess()
+			if accessErr != nil {
+				log.Fatal(accessErr)
+			}
+			utils.InitalizeManagementClusterAcces(scheme, restConfig, clientSet, c)
 			err = commands.LogLevel(ctx, args, logger)
+		case "completion":
+			// Scripts are generated without reaching the cluster. Candidates
+			// are listed from it when reachable.
+			if len(args) > 1 && args[1] == "__complete" {
+				scheme, restConfig, clientSet, c, accessErr := managementClusterAccess()
+				if accessErr == nil {
+					utils.InitalizeManagementClusterAcces(scheme, restConfig, clientSet, c)
+				}
+			}
+			err = commands.Completion(ctx, args, logger)
 		default:
 			err = fmt.Errorf("unknown command: %q\n%s", command, doc)
 		}
@@ -99,28 +113,30 @@ Description:
 	}
 }
 
-func initializeManagementClusterAccess() (*runtime.Scheme, *rest.Config, *kubernetes.Clientset, client.Client) {
+// managementClusterAccess returns what is needed to reach the management
+// cluster. It fails if no cluster configuration is found.
+func managementClusterAccess() (*runtime.Scheme, *rest.Config, *kubernetes.Clientset, client.Client, error) {
 	scheme, err := utils.GetScheme()
 	if err != nil {
-		werr := fmt.Errorf("failed to get scheme %w", err)
-		log.Fatal(werr)
+		return nil, nil, nil, nil, fmt.Errorf("failed to get scheme %w", err)
 	}
 
-	restConfig := ctrl.GetConfigOrDie()
+	restConfig, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("failed to get cluster configuration: %w", err)
+	}
 	restConfig.QPS = 100
 	restConfig.Burst = 100
 
 	cs, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		werr := fmt.Errorf("error in getting access to K8S: %w", err)
-		log.Fatal(werr)
+		return nil, nil, nil, nil, fmt.Errorf("error in getting access to K8S: %w", err)
 	}
 
 	c, err := client.New(restConfig, client.Options{Scheme: scheme})
 	if err != nil {
-		werr := fmt.Errorf("failed to connect: %w", err)
-		log.Fatal(werr)
+		return nil, nil, nil, nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
-	return scheme, restConfig, cs, c
+	return scheme, restConfig, cs, c, nil
 }